@@ -0,0 +1,57 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"trabalho-03/internal/auth"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+)
+
+// AuthMiddleware stores the claims of a valid "Authorization: Bearer" header in
+// the request context. Requests without one still reach the schema so the
+// playground and introspection work; @auth fields reject them.
+func AuthMiddleware(authenticator *auth.Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if header := r.Header.Get("Authorization"); header != "" {
+			if claims, err := authenticator.ParseAuthorization(header); err == nil {
+				r = r.WithContext(auth.WithClaims(r.Context(), claims))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WebsocketInit authenticates subscriptions from the "Authorization" entry of
+// the connection_init payload, since browsers cannot set headers on WebSockets.
+func WebsocketInit(authenticator *auth.Authenticator) transport.WebsocketInitFunc {
+	return func(ctx context.Context, payload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+		if header := payload.Authorization(); header != "" {
+			claims, err := authenticator.ParseAuthorization(header)
+			if err != nil {
+				return ctx, nil, err
+			}
+			ctx = auth.WithClaims(ctx, claims)
+		}
+		return ctx, nil, nil
+	}
+}
+
+// AuthDirective implements @auth(requires: Role).
+func AuthDirective(ctx context.Context, obj any, next gqlgen.Resolver, requires *Role) (any, error) {
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+	if requires != nil && *requires == RoleAdmin && !claims.IsAdmin() {
+		return nil, auth.ErrForbidden
+	}
+	return next(ctx)
+}
+
+// actor returns the claims checked by AuthDirective.
+func actor(ctx context.Context) *auth.Claims {
+	claims, _ := auth.ClaimsFromContext(ctx)
+	return claims
+}
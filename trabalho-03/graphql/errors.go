@@ -0,0 +1,62 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"log"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/usecase/validation"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// presentError adds one GraphQL error per invalid field so clients can read the
+// offending field from the error extensions. Other errors are returned as is
+// and mapped by ErrorPresenter.
+func presentError(ctx context.Context, err error) error {
+	var validationErrs validation.Errors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	for _, fe := range validationErrs {
+		gqlgen.AddError(ctx, &gqlerror.Error{
+			Message: fe.Field + ": " + fe.Message,
+			Path:    gqlgen.GetPath(ctx),
+			Extensions: map[string]any{
+				"code":  string(domain.KindInvalid),
+				"field": fe.Field,
+			},
+		})
+	}
+	return nil
+}
+
+// ErrorPresenter gives every error returned by a resolver or directive the
+// code of its domain kind in the extensions. Errors that already carry a code,
+// such as GraphQL validation failures, are left alone; internal errors are
+// logged and their message is hidden.
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	presented := gqlgen.DefaultErrorPresenter(ctx, err)
+	if _, ok := presented.Extensions["code"]; ok {
+		return presented
+	}
+
+	// Resolver errors arrive wrapped with their path, which would otherwise
+	// prefix the message.
+	var wrapped *gqlerror.Error
+	if errors.As(err, &wrapped) && wrapped.Err != nil {
+		err = wrapped.Err
+	}
+	kind, message := domain.Describe(err)
+	if kind == domain.KindInternal {
+		log.Printf("GraphQL resolver failed at %v: %v", presented.Path, err)
+	}
+	presented.Message = message
+	if presented.Extensions == nil {
+		presented.Extensions = map[string]any{}
+	}
+	presented.Extensions["code"] = string(kind)
+	return presented
+}
@@ -0,0 +1,253 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+	"trabalho-03/internal/auth"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/event"
+	"trabalho-03/internal/repository"
+	"trabalho-03/internal/usecase"
+
+	"github.com/99designs/gqlgen/client"
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/vektah/gqlparser/v2/ast"
+	"gorm.io/gorm"
+)
+
+// countingCustomerRepository records every FindByIDs batch.
+type countingCustomerRepository struct {
+	repository.CustomerRepository
+	mutex   sync.Mutex
+	batches [][]string
+}
+
+func (r *countingCustomerRepository) FindByIDs(ctx context.Context, ids []string) ([]domain.Customer, error) {
+	r.mutex.Lock()
+	r.batches = append(r.batches, append([]string(nil), ids...))
+	r.mutex.Unlock()
+	return r.CustomerRepository.FindByIDs(ctx, ids)
+}
+
+type testServer struct {
+	client    *client.Client
+	customers *countingCustomerRepository
+}
+
+func newTestServer(t *testing.T, extensions ...gqlgen.HandlerExtension) *testServer {
+	t.Helper()
+
+	orders := repository.NewMemoryOrderRepository()
+	customers := &countingCustomerRepository{CustomerRepository: repository.NewMemoryCustomerRepository()}
+	resolver := &Resolver{
+		OrderUseCase:    usecase.NewOrderUseCase(orders, event.NewBus()),
+		CustomerUseCase: usecase.NewCustomerUseCase(customers),
+		EventBus:        event.NewBus(),
+	}
+
+	srv := handler.New(NewExecutableSchema(NewConfig(resolver)))
+	srv.AddTransport(transport.POST{})
+	srv.SetErrorPresenter(ErrorPresenter)
+	srv.Use(Dataloaders{CustomerUseCase: resolver.CustomerUseCase})
+	for _, ext := range extensions {
+		srv.Use(ext)
+	}
+
+	authenticator := auth.NewAuthenticator("secret")
+	token, err := authenticator.Issue("", auth.RoleAdmin, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+
+	ctx := auth.WithClaims(context.Background(), &auth.Claims{Role: auth.RoleAdmin})
+	for _, customer := range []domain.Customer{
+		{ID: "customer-1", Name: "Ana", Email: "ana@example.com"},
+		{ID: "customer-2", Name: "Bruno", Email: "bruno@example.com"},
+	} {
+		if err := resolver.CustomerUseCase.UpsertCustomer(ctx, &customer); err != nil {
+			t.Fatalf("Failed to create customer: %v", err)
+		}
+	}
+	for _, customerID := range []string{"customer-1", "customer-2", "customer-1", "customer-3"} {
+		order := &domain.Order{
+			CustomerID: customerID,
+			Status:     domain.OrderStatusPending,
+			Items:      []domain.OrderItem{{ProductID: "product-1", Quantity: 1, UnitPriceCents: 100}},
+		}
+		if err := resolver.OrderUseCase.CreateOrder(ctx, order, ""); err != nil {
+			t.Fatalf("Failed to create order: %v", err)
+		}
+	}
+
+	return &testServer{
+		client:    client.New(AuthMiddleware(authenticator, srv), client.AddHeader("Authorization", "Bearer "+token)),
+		customers: customers,
+	}
+}
+
+func errorCodes(t *testing.T, resp *client.Response) []string {
+	t.Helper()
+	var errs []struct {
+		Extensions struct {
+			Code string `json:"code"`
+		} `json:"extensions"`
+	}
+	if err := json.Unmarshal(resp.Errors, &errs); err != nil {
+		t.Fatalf("Failed to decode errors %s: %v", resp.Errors, err)
+	}
+	codes := make([]string, 0, len(errs))
+	for _, e := range errs {
+		codes = append(codes, e.Extensions.Code)
+	}
+	return codes
+}
+
+func TestOrderCustomer_IsBatchedPerRequest(t *testing.T) {
+	server := newTestServer(t)
+	server.customers.batches = nil
+
+	var resp struct {
+		Orders []struct {
+			CustomerID string
+			Customer   *struct{ Name string }
+		}
+	}
+	server.client.MustPost(`{ orders { customerId customer { name } } }`, &resp)
+
+	if len(resp.Orders) != 4 {
+		t.Fatalf("Expected 4 orders, got %d", len(resp.Orders))
+	}
+	names := map[string]string{"customer-1": "Ana", "customer-2": "Bruno"}
+	for _, order := range resp.Orders {
+		if order.CustomerID == "customer-3" {
+			if order.Customer != nil {
+				t.Errorf("Expected no customer for an unregistered id, got %+v", order.Customer)
+			}
+			continue
+		}
+		if order.Customer == nil || order.Customer.Name != names[order.CustomerID] {
+			t.Errorf("Expected customer %s, got %+v", names[order.CustomerID], order.Customer)
+		}
+	}
+	if len(server.customers.batches) != 1 || len(server.customers.batches[0]) != 3 {
+		t.Errorf("Expected a single batch of 3 distinct ids, got %v", server.customers.batches)
+	}
+}
+
+func TestDepthLimit_RejectsDeepOperations(t *testing.T) {
+	server := newTestServer(t, DepthLimit{Max: 2})
+
+	resp, err := server.client.RawPost(`{ orders { ...withCustomer } } fragment withCustomer on Order { customer { name } }`)
+	if err != nil {
+		t.Fatalf("Expected no transport error, got %v", err)
+	}
+	if codes := errorCodes(t, resp); len(codes) != 1 || codes[0] != "DEPTH_LIMIT_EXCEEDED" {
+		t.Errorf("Expected DEPTH_LIMIT_EXCEEDED, got %s", resp.Errors)
+	}
+
+	resp, err = server.client.RawPost(`{ orders { id customer { __typename } } }`)
+	if err != nil || len(resp.Errors) > 0 {
+		t.Errorf("Expected depth 2 to be allowed, got %v %s", err, resp.Errors)
+	}
+}
+
+func TestComplexityLimit_CountsListsAsMultipleElements(t *testing.T) {
+	server := newTestServer(t, extension.FixedComplexityLimit(100))
+
+	resp, err := server.client.RawPost(`{ orders { id items { productId quantity unitPriceCents totalCents } } }`)
+	if err != nil {
+		t.Fatalf("Expected no transport error, got %v", err)
+	}
+	if codes := errorCodes(t, resp); len(codes) != 1 || codes[0] != "COMPLEXITY_LIMIT_EXCEEDED" {
+		t.Errorf("Expected COMPLEXITY_LIMIT_EXCEEDED, got %s", resp.Errors)
+	}
+
+	resp, err = server.client.RawPost(`{ orders { id status } }`)
+	if err != nil || len(resp.Errors) > 0 {
+		t.Errorf("Expected a cheap query to be allowed, got %v %s", err, resp.Errors)
+	}
+}
+
+func TestErrors_CarryTheDomainKindAsCode(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := map[string]struct {
+		query    string
+		wantCode domain.Kind
+	}{
+		"not found":     {`mutation { updateOrderStatus(id: "42", status: "shipped") { id } }`, domain.KindNotFound},
+		"invalid id":    {`mutation { updateOrderStatus(id: "x", status: "shipped") { id } }`, domain.KindInvalid},
+		"invalid field": {`mutation { updateOrderStatus(id: "1", status: "lost") { id } }`, domain.KindInvalid},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := server.client.RawPost(tt.query)
+			if err != nil {
+				t.Fatalf("Expected no transport error, got %v", err)
+			}
+			if codes := errorCodes(t, resp); len(codes) != 1 || codes[0] != string(tt.wantCode) {
+				t.Errorf("Expected %s, got %s", tt.wantCode, resp.Errors)
+			}
+		})
+	}
+}
+
+func TestCreateOrder_RejectsQuantitiesBeyondInt32(t *testing.T) {
+	server := newTestServer(t)
+
+	// 2^32 + 1 would wrap around to a quantity of 1 when narrowed to int32.
+	resp, err := server.client.RawPost(`mutation($quantity: Int!) {
+		createOrder(input: {customerId: "customer-1", status: "pending",
+			items: [{productId: "product-1", quantity: $quantity, unitPriceCents: 100}]}) { id }
+	}`, client.Var("quantity", 4294967297))
+	if err != nil {
+		t.Fatalf("Expected no transport error, got %v", err)
+	}
+	if codes := errorCodes(t, resp); len(codes) != 1 || codes[0] != string(domain.KindInvalid) {
+		t.Errorf("Expected a %s error, got %s", domain.KindInvalid, resp.Errors)
+	}
+
+	var orders struct{ Orders []struct{ ID string } }
+	server.client.MustPost(`{ orders { id } }`, &orders)
+	if len(orders.Orders) != 4 {
+		t.Errorf("Expected only the 4 seeded orders, got %d", len(orders.Orders))
+	}
+}
+
+func TestToGraphQLOrder_DeletedAtIsUTC(t *testing.T) {
+	deletedAt := time.Date(2024, 5, 1, 9, 30, 0, 0, time.FixedZone("BRT", -3*60*60))
+	order := &domain.Order{ID: 1, DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}}
+
+	got := toGraphQLOrder(order).DeletedAt
+	if got == nil || *got != "2024-05-01T12:30:00Z" {
+		t.Errorf("Expected deletedAt 2024-05-01T12:30:00Z, got %v", got)
+	}
+}
+
+func TestTelemetry_MetricsIgnoreTheOperationName(t *testing.T) {
+	operation := func(name string) *gqlgen.OperationContext {
+		return &gqlgen.OperationContext{Operation: &ast.OperationDefinition{
+			Operation:    ast.Mutation,
+			Name:         name,
+			SelectionSet: ast.SelectionSet{&ast.Field{Alias: "created", Name: "createOrder"}},
+		}}
+	}
+
+	for _, name := range []string{"", "CreateOrder", "random-1234"} {
+		if got := metricOperation(operation(name)); got != "mutation createOrder" {
+			t.Errorf("Expected metric operation %q for name %q, got %q", "mutation createOrder", name, got)
+		}
+	}
+	if got := operationName(operation("random-1234")); got != "mutation random-1234" {
+		t.Errorf("Expected the span to keep the operation name, got %q", got)
+	}
+	if got := operationName(operation("")); got != "mutation createOrder" {
+		t.Errorf("Expected an anonymous span to be named after its root field, got %q", got)
+	}
+}
@@ -0,0 +1,52 @@
+package graphql
+
+import (
+	"net/http"
+	"time"
+	"trabalho-03/internal/auth"
+
+	gqlhandler "github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// HandlerOptions bounds the work a single GraphQL request may cause.
+type HandlerOptions struct {
+	Timeout       time.Duration
+	MaxComplexity int
+	MaxDepth      int
+	APQCacheSize  int
+}
+
+// NewHandler mirrors gqlhandler.NewDefaultServer, adding the @auth directive,
+// authentication of WebSocket subscriptions, complexity and depth limits,
+// per-response dataloaders and telemetry. The playground is served at / and
+// the API at /query.
+func NewHandler(resolver *Resolver, authenticator *auth.Authenticator, options HandlerOptions) http.Handler {
+	srv := gqlhandler.New(NewExecutableSchema(NewConfig(resolver)))
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		InitFunc:              WebsocketInit(authenticator),
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+	srv.SetErrorPresenter(ErrorPresenter)
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.AutomaticPersistedQuery{Cache: lru.New[string](options.APQCacheSize)})
+	srv.Use(extension.FixedComplexityLimit(options.MaxComplexity))
+	srv.Use(DepthLimit{Max: options.MaxDepth})
+	srv.Use(Dataloaders{CustomerUseCase: resolver.CustomerUseCase})
+	srv.Use(Telemetry{})
+
+	mux := http.NewServeMux()
+	mux.Handle("/", playground.Handler("GraphQL playground", "/query"))
+	mux.Handle("/query", TimeoutMiddleware(options.Timeout, AuthMiddleware(authenticator, srv)))
+
+	return mux
+}
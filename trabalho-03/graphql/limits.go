@@ -0,0 +1,92 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// listComplexityFactor is the number of elements assumed for unpaginated lists
+// when computing query complexity.
+const listComplexityFactor = 10
+
+// NewConfig wires the resolvers, the @auth directive and the complexity of list
+// fields, to be used together with extension.FixedComplexityLimit.
+func NewConfig(resolver *Resolver) Config {
+	config := Config{
+		Resolvers:  resolver,
+		Directives: DirectiveRoot{Auth: AuthDirective},
+	}
+	config.Complexity.Query.Orders = func(childComplexity int, _ *bool) int {
+		return listComplexity(childComplexity)
+	}
+	config.Complexity.Query.OrderStats = func(childComplexity int, _ ReportGroupBy, _ *string, _ *string) int {
+		return listComplexity(childComplexity)
+	}
+	config.Complexity.Order.Items = listComplexity
+	return config
+}
+
+func listComplexity(childComplexity int) int {
+	return 1 + listComplexityFactor*childComplexity
+}
+
+// DepthLimit rejects operations whose selections nest deeper than Max fields.
+// Introspection fields are not counted; they are governed by the
+// Introspection extension instead.
+type DepthLimit struct {
+	Max int
+}
+
+var _ interface {
+	gqlgen.HandlerExtension
+	gqlgen.OperationContextMutator
+} = DepthLimit{}
+
+func (DepthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (d DepthLimit) Validate(gqlgen.ExecutableSchema) error {
+	if d.Max < 1 {
+		return fmt.Errorf("depth limit must be positive, got %d", d.Max)
+	}
+	return nil
+}
+
+func (d DepthLimit) MutateOperationContext(ctx context.Context, oc *gqlgen.OperationContext) *gqlerror.Error {
+	if depth := selectionDepth(oc.Operation.SelectionSet); depth > d.Max {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.Max)
+		err.Extensions = map[string]any{"code": "DEPTH_LIMIT_EXCEEDED"}
+		return err
+	}
+	return nil
+}
+
+// selectionDepth counts nested fields; fragments add no depth of their own.
+// Validation has already rejected fragment cycles.
+func selectionDepth(selections ast.SelectionSet) int {
+	deepest := 0
+	for _, selection := range selections {
+		depth := 0
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			depth = 1 + selectionDepth(s.SelectionSet)
+		case *ast.InlineFragment:
+			depth = selectionDepth(s.SelectionSet)
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				depth = selectionDepth(s.Definition.SelectionSet)
+			}
+		}
+		deepest = max(deepest, depth)
+	}
+	return deepest
+}
@@ -0,0 +1,71 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"time"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/usecase"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/vikstrous/dataloadgen"
+)
+
+type loadersKey struct{}
+
+// Loaders batches the lookups made while resolving one response, so a list of
+// N orders costs one customer query instead of N.
+type Loaders struct {
+	Customers *dataloadgen.Loader[string, domain.Customer]
+}
+
+func newLoaders(customers *usecase.CustomerUseCase) *Loaders {
+	return &Loaders{
+		Customers: dataloadgen.NewMappedLoader(func(ctx context.Context, ids []string) (map[string]domain.Customer, error) {
+			found, err := customers.GetCustomers(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[string]domain.Customer, len(found))
+			for _, customer := range found {
+				byID[customer.ID] = customer
+			}
+			return byID, nil
+		}, dataloadgen.WithWait(time.Millisecond)),
+	}
+}
+
+// Dataloaders is a gqlgen extension that gives every response its own Loaders.
+// Scoping them per response (rather than per connection) keeps subscription
+// events from being served out of a stale cache.
+type Dataloaders struct {
+	CustomerUseCase *usecase.CustomerUseCase
+}
+
+var _ interface {
+	gqlgen.HandlerExtension
+	gqlgen.ResponseInterceptor
+} = Dataloaders{}
+
+func (Dataloaders) ExtensionName() string {
+	return "Dataloaders"
+}
+
+func (d Dataloaders) Validate(gqlgen.ExecutableSchema) error {
+	if d.CustomerUseCase == nil {
+		return errors.New("dataloaders need a CustomerUseCase")
+	}
+	return nil
+}
+
+func (d Dataloaders) InterceptResponse(ctx context.Context, next gqlgen.ResponseHandler) *gqlgen.Response {
+	return next(context.WithValue(ctx, loadersKey{}, newLoaders(d.CustomerUseCase)))
+}
+
+func loadersFor(ctx context.Context) (*Loaders, error) {
+	loaders, ok := ctx.Value(loadersKey{}).(*Loaders)
+	if !ok {
+		return nil, errors.New("dataloaders are not installed on this server")
+	}
+	return loaders, nil
+}
@@ -0,0 +1,118 @@
+package graphql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/usecase/validation"
+)
+
+// toDomainItems converts the createOrder items. GraphQL Int is a Go int and
+// variables are not range-checked, so quantities outside int32 are reported as
+// validation.Errors instead of wrapping around.
+func toDomainItems(items []*OrderItemInput) ([]domain.OrderItem, error) {
+	domainItems := make([]domain.OrderItem, 0, len(items))
+	var errs validation.Errors
+	for i, item := range items {
+		if item.Quantity < math.MinInt32 || item.Quantity > math.MaxInt32 {
+			errs = append(errs, validation.FieldError{
+				Field:   fmt.Sprintf("items[%d].quantity", i),
+				Message: fmt.Sprintf("must be between 1 and %d", domain.MaxItemQuantity),
+			})
+			continue
+		}
+		domainItems = append(domainItems, domain.OrderItem{
+			ProductID:      item.ProductID,
+			Quantity:       int32(item.Quantity),
+			UnitPriceCents: int64(item.UnitPriceCents),
+		})
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return domainItems, nil
+}
+
+func toGraphQLOrder(order *domain.Order) *Order {
+	gqlOrder := &Order{
+		ID:          strconv.Itoa(int(order.ID)),
+		CustomerID:  order.CustomerID,
+		AmountCents: int(order.AmountCents),
+		Status:      order.Status,
+		Version:     int(order.Version),
+		CreatedAt:   order.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:   order.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		Items:       make([]*OrderItem, 0, len(order.Items)),
+	}
+	if order.DeletedAt.Valid {
+		deletedAt := order.DeletedAt.Time.UTC().Format(time.RFC3339)
+		gqlOrder.DeletedAt = &deletedAt
+	}
+	for _, item := range order.Items {
+		gqlOrder.Items = append(gqlOrder.Items, &OrderItem{
+			ProductID:      item.ProductID,
+			Quantity:       int(item.Quantity),
+			UnitPriceCents: int(item.UnitPriceCents),
+			TotalCents:     int(item.TotalCents()),
+		})
+	}
+	return gqlOrder
+}
+
+func toGraphQLCustomer(customer *domain.Customer) *Customer {
+	return &Customer{
+		ID:    customer.ID,
+		Name:  customer.Name,
+		Email: customer.Email,
+	}
+}
+
+// toDomainReportFilter parses the orderStats arguments, reporting malformed
+// bounds as validation.Errors.
+func toDomainReportFilter(groupBy ReportGroupBy, from, to *string) (domain.ReportFilter, error) {
+	filter := domain.ReportFilter{GroupBy: strings.ToLower(string(groupBy))}
+	var errs validation.Errors
+	var err error
+	if from != nil {
+		if filter.From, err = domain.ParseReportTime(*from, false); err != nil {
+			errs = append(errs, validation.FieldError{Field: "from", Message: err.Error()})
+		}
+	}
+	if to != nil {
+		if filter.To, err = domain.ParseReportTime(*to, true); err != nil {
+			errs = append(errs, validation.FieldError{Field: "to", Message: err.Error()})
+		}
+	}
+	if len(errs) > 0 {
+		return filter, errs
+	}
+	return filter, nil
+}
+
+func toGraphQLOrderStats(stats *domain.OrderStats) *OrderStats {
+	return &OrderStats{
+		Key:                stats.Key,
+		Count:              int(stats.Count),
+		TotalAmountCents:   int(stats.TotalAmountCents),
+		AverageAmountCents: stats.AverageAmountCents,
+	}
+}
+
+// toDomainOrderTarget parses the order id and optional expected version taken
+// by the mutations on an existing order; a nil version skips the check.
+func toDomainOrderTarget(id string, version *int) (uint, uint, error) {
+	orderID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, 0, domain.Errorf(domain.KindInvalid, "invalid order id %q", id)
+	}
+	if version == nil {
+		return uint(orderID), 0, nil
+	}
+	if *version < 1 {
+		return 0, 0, domain.Errorf(domain.KindInvalid, "invalid version %d", *version)
+	}
+	return uint(orderID), uint(*version), nil
+}
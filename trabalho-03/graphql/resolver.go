@@ -1,11 +1,16 @@
 package graphql
 
-import "trabalho-03/internal/usecase"
+import (
+	"trabalho-03/internal/event"
+	"trabalho-03/internal/usecase"
+)
 
 // This file will not be regenerated automatically.
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct{
-	OrderUseCase *usecase.OrderUseCase
+	OrderUseCase    *usecase.OrderUseCase
+	CustomerUseCase *usecase.CustomerUseCase
+	EventBus        *event.Bus
 }
@@ -6,61 +6,190 @@ package graphql
 
 import (
 	"context"
+	"errors"
 	"strconv"
+	"trabalho-03/internal/auth"
 	"trabalho-03/internal/domain"
+
+	"github.com/vikstrous/dataloadgen"
 )
 
 // CreateOrder is the resolver for the createOrder field.
 func (r *mutationResolver) CreateOrder(ctx context.Context, input CreateOrderInput) (*Order, error) {
+	items, err := toDomainItems(input.Items)
+	if err != nil {
+		return nil, presentError(ctx, err)
+	}
 	order := &domain.Order{
 		CustomerID: input.CustomerID,
-		Amount:     input.Amount,
 		Status:     input.Status,
+		Items:      items,
+	}
+
+	var idempotencyKey string
+	if input.IdempotencyKey != nil {
+		idempotencyKey = *input.IdempotencyKey
 	}
 
-	err := r.OrderUseCase.CreateOrder(order)
+	if err := r.OrderUseCase.CreateOrder(ctx, order, idempotencyKey); err != nil {
+		return nil, presentError(ctx, err)
+	}
+
+	return toGraphQLOrder(order), nil
+}
+
+// UpdateOrderStatus is the resolver for the updateOrderStatus field.
+func (r *mutationResolver) UpdateOrderStatus(ctx context.Context, id string, status string, version *int) (*Order, error) {
+	orderID, expectedVersion, err := toDomainOrderTarget(id, version)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Order{
-		ID:         strconv.Itoa(int(order.ID)),
-		CustomerID: order.CustomerID,
-		Amount:     order.Amount,
-		Status:     order.Status,
-		CreatedAt:  order.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:  order.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-	}, nil
+	order, err := r.OrderUseCase.UpdateOrderStatus(ctx, orderID, status, expectedVersion)
+	if err != nil {
+		return nil, presentError(ctx, err)
+	}
+
+	return toGraphQLOrder(order), nil
 }
 
-// Orders is the resolver for the orders field.
-func (r *queryResolver) Orders(ctx context.Context) ([]*Order, error) {
-	orders, err := r.OrderUseCase.ListOrders()
+// DeleteOrder is the resolver for the deleteOrder field.
+func (r *mutationResolver) DeleteOrder(ctx context.Context, id string, version *int) (*Order, error) {
+	orderID, expectedVersion, err := toDomainOrderTarget(id, version)
 	if err != nil {
 		return nil, err
 	}
 
+	order, err := r.OrderUseCase.DeleteOrder(ctx, orderID, expectedVersion)
+	if err != nil {
+		return nil, presentError(ctx, err)
+	}
+
+	return toGraphQLOrder(order), nil
+}
+
+// RestoreOrder is the resolver for the restoreOrder field.
+func (r *mutationResolver) RestoreOrder(ctx context.Context, id string, version *int) (*Order, error) {
+	orderID, expectedVersion, err := toDomainOrderTarget(id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := r.OrderUseCase.RestoreOrder(ctx, orderID, expectedVersion)
+	if err != nil {
+		return nil, presentError(ctx, err)
+	}
+
+	return toGraphQLOrder(order), nil
+}
+
+// UpsertCustomer is the resolver for the upsertCustomer field.
+func (r *mutationResolver) UpsertCustomer(ctx context.Context, input UpsertCustomerInput) (*Customer, error) {
+	customer := &domain.Customer{
+		ID:    input.ID,
+		Name:  input.Name,
+		Email: input.Email,
+	}
+
+	err := r.CustomerUseCase.UpsertCustomer(ctx, customer)
+	if err != nil {
+		return nil, presentError(ctx, err)
+	}
+
+	return toGraphQLCustomer(customer), nil
+}
+
+// Customer is the resolver for the customer field.
+func (r *orderResolver) Customer(ctx context.Context, obj *Order) (*Customer, error) {
+	loaders, err := loadersFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := loaders.Customers.Load(ctx, obj.CustomerID)
+	if errors.Is(err, dataloadgen.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, presentError(ctx, err)
+	}
+
+	return toGraphQLCustomer(&customer), nil
+}
+
+// Orders is the resolver for the orders field.
+func (r *queryResolver) Orders(ctx context.Context, includeDeleted *bool) ([]*Order, error) {
+	orders, err := r.OrderUseCase.ListOrders(ctx, includeDeleted != nil && *includeDeleted)
+	if err != nil {
+		return nil, presentError(ctx, err)
+	}
+
 	var gqlOrders []*Order
-	for _, order := range orders {
-		gqlOrder := &Order{
-			ID:         strconv.Itoa(int(order.ID)),
-			CustomerID: order.CustomerID,
-			Amount:     order.Amount,
-			Status:     order.Status,
-			CreatedAt:  order.CreatedAt.Format("2006-01-02T15:04:05Z"),
-			UpdatedAt:  order.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-		}
-		gqlOrders = append(gqlOrders, gqlOrder)
+	for i := range orders {
+		gqlOrders = append(gqlOrders, toGraphQLOrder(&orders[i]))
 	}
 
 	return gqlOrders, nil
 }
 
+// OrderStats is the resolver for the orderStats field.
+func (r *queryResolver) OrderStats(ctx context.Context, groupBy ReportGroupBy, from *string, to *string) ([]*OrderStats, error) {
+	filter, err := toDomainReportFilter(groupBy, from, to)
+	if err != nil {
+		return nil, presentError(ctx, err)
+	}
+
+	stats, err := r.OrderUseCase.OrderStats(ctx, filter)
+	if err != nil {
+		return nil, presentError(ctx, err)
+	}
+
+	gqlStats := make([]*OrderStats, 0, len(stats))
+	for i := range stats {
+		gqlStats = append(gqlStats, toGraphQLOrderStats(&stats[i]))
+	}
+
+	return gqlStats, nil
+}
+
+// OrderCreated is the resolver for the orderCreated field.
+func (r *subscriptionResolver) OrderCreated(ctx context.Context, customerID *string) (<-chan *Order, error) {
+	claims := actor(ctx)
+	if customerID != nil && !claims.CanAccess(*customerID) {
+		return nil, presentError(ctx, auth.ErrForbidden)
+	}
+
+	return r.subscribeOrders(ctx, domain.EventOrderCreated, func(order domain.Order) bool {
+		return claims.CanAccess(order.CustomerID) && (customerID == nil || order.CustomerID == *customerID)
+	}), nil
+}
+
+// OrderStatusChanged is the resolver for the orderStatusChanged field.
+func (r *subscriptionResolver) OrderStatusChanged(ctx context.Context, id string) (<-chan *Order, error) {
+	orderID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, domain.Errorf(domain.KindInvalid, "invalid order id %q", id)
+	}
+
+	claims := actor(ctx)
+	return r.subscribeOrders(ctx, domain.EventOrderStatusChanged, func(order domain.Order) bool {
+		return order.ID == uint(orderID) && claims.CanAccess(order.CustomerID)
+	}), nil
+}
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
+// Order returns OrderResolver implementation.
+func (r *Resolver) Order() OrderResolver { return &orderResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
 type mutationResolver struct{ *Resolver }
+type orderResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
@@ -0,0 +1,41 @@
+package graphql
+
+import (
+	"context"
+	"trabalho-03/internal/domain"
+)
+
+const subscriptionBuffer = 16
+
+// subscribeOrders forwards bus events of the given type that satisfy match
+// until the client disconnects or the bus is closed.
+func (r *Resolver) subscribeOrders(ctx context.Context, eventType string, match func(domain.Order) bool) <-chan *Order {
+	events, unsubscribe := r.EventBus.Subscribe(subscriptionBuffer)
+	orders := make(chan *Order, 1)
+
+	go func() {
+		defer close(orders)
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case evt, ok := <-events:
+				if !ok {
+					return
+				}
+				if evt.Type != eventType || !match(evt.Order) {
+					continue
+				}
+				select {
+				case orders <- toGraphQLOrder(&evt.Order):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return orders
+}
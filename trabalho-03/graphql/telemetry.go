@@ -0,0 +1,99 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/telemetry"
+
+	gqlgen "github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// clientErrorCodes are error extension codes caused by the request rather than
+// by the server; they do not count as errors in the RED metrics.
+var clientErrorCodes = map[domain.Kind]bool{
+	domain.KindInvalid:         true,
+	domain.KindNotFound:        true,
+	domain.KindConflict:        true,
+	domain.KindPrecondition:    true,
+	domain.KindUnauthenticated: true,
+	domain.KindForbidden:       true,
+	domain.KindCanceled:        true,
+}
+
+// Telemetry is a gqlgen extension that traces queries and mutations and
+// records their RED metrics. Subscriptions are skipped: they last as long as
+// the WebSocket connection.
+type Telemetry struct{}
+
+var _ interface {
+	gqlgen.HandlerExtension
+	gqlgen.OperationInterceptor
+} = Telemetry{}
+
+func (Telemetry) ExtensionName() string {
+	return "Telemetry"
+}
+
+func (Telemetry) Validate(gqlgen.ExecutableSchema) error {
+	return nil
+}
+
+func (Telemetry) InterceptOperation(ctx context.Context, next gqlgen.OperationHandler) gqlgen.ResponseHandler {
+	oc := gqlgen.GetOperationContext(ctx)
+	if oc.Operation == nil || oc.Operation.Operation == ast.Subscription {
+		return next(ctx)
+	}
+
+	ctx, request := telemetry.StartRequest(ctx, telemetry.TransportGraphQL, operationName(oc), propagation.HeaderCarrier(oc.Headers))
+	request.SetMetricOperation(metricOperation(oc))
+	handler := next(ctx)
+	ended := false
+	return func(ctx context.Context) *gqlgen.Response {
+		resp := handler(ctx)
+		if !ended {
+			ended = true
+			endRequest(request, resp)
+		}
+		return resp
+	}
+}
+
+// operationName names the span after the operation type and its name or, for
+// anonymous operations, its first root field, e.g. "mutation createOrder".
+func operationName(oc *gqlgen.OperationContext) string {
+	if oc.Operation.Name != "" {
+		return string(oc.Operation.Operation) + " " + oc.Operation.Name
+	}
+	return metricOperation(oc)
+}
+
+// metricOperation labels the metrics after the operation type and its first
+// root field, never the client-chosen operation name: root fields are limited
+// by the schema, so the number of metric series is too.
+func metricOperation(oc *gqlgen.OperationContext) string {
+	name := ""
+	if len(oc.Operation.SelectionSet) > 0 {
+		if field, ok := oc.Operation.SelectionSet[0].(*ast.Field); ok {
+			name = field.Name
+		}
+	}
+	return string(oc.Operation.Operation) + " " + name
+}
+
+func endRequest(request *telemetry.Request, resp *gqlgen.Response) {
+	if resp == nil || len(resp.Errors) == 0 {
+		request.End("ok", nil)
+		return
+	}
+
+	var serverErrs []error
+	for _, err := range resp.Errors {
+		if code, _ := err.Extensions["code"].(string); !clientErrorCodes[domain.Kind(code)] {
+			serverErrs = append(serverErrs, err)
+		}
+	}
+	request.End("error", errors.Join(serverErrs...))
+}
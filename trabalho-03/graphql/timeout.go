@@ -0,0 +1,23 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TimeoutMiddleware bounds queries and mutations with d. WebSocket upgrades are
+// left alone: a subscription lives as long as its connection.
+func TimeoutMiddleware(d time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Authenticator issues and verifies HS256-signed bearer tokens.
+type Authenticator struct {
+	secret []byte
+}
+
+func NewAuthenticator(secret string) *Authenticator {
+	return &Authenticator{secret: []byte(secret)}
+}
+
+func (a *Authenticator) Issue(customerID, role string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		CustomerID: customerID,
+		Role:       role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   customerID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	if err := claims.validate(); err != nil {
+		return "", err
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(a.secret)
+}
+
+func (a *Authenticator) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		return a.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	if err := claims.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	return claims, nil
+}
+
+// ParseAuthorization parses an "Authorization: Bearer <token>" header value.
+func (a *Authenticator) ParseAuthorization(header string) (*Claims, error) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, ErrUnauthenticated
+	}
+	return a.Parse(strings.TrimSpace(token))
+}
+
+func (c *Claims) validate() error {
+	switch c.Role {
+	case RoleAdmin:
+		return nil
+	case RoleCustomer:
+		if c.CustomerID == "" {
+			return errors.New("customer tokens must carry a customer_id")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown role %q", c.Role)
+	}
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestAuthenticator_IssueAndParse(t *testing.T) {
+	authenticator := NewAuthenticator("secret")
+
+	token, err := authenticator.Issue("customer-1", RoleCustomer, time.Hour)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	claims, err := authenticator.ParseAuthorization("Bearer " + token)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if claims.CustomerID != "customer-1" || claims.Role != RoleCustomer {
+		t.Errorf("Expected customer-1/customer claims, got %+v", claims)
+	}
+	if !claims.CanAccess("customer-1") || claims.CanAccess("customer-2") {
+		t.Error("Expected customer to access only its own orders")
+	}
+}
+
+func TestAuthenticator_RejectsInvalidTokens(t *testing.T) {
+	authenticator := NewAuthenticator("secret")
+
+	expired, _ := authenticator.Issue("customer-1", RoleCustomer, -time.Minute)
+	otherSecret, _ := NewAuthenticator("other").Issue("customer-1", RoleCustomer, time.Hour)
+	otherAlgorithm, _ := jwt.NewWithClaims(jwt.SigningMethodHS384, &Claims{
+		CustomerID:       "customer-1",
+		Role:             RoleCustomer,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}).SignedString([]byte("secret"))
+	withoutExpiry, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{Role: RoleAdmin}).SignedString([]byte("secret"))
+	unknownRole, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		Role:             "root",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}).SignedString([]byte("secret"))
+
+	headers := map[string]string{
+		"missing":         "",
+		"wrong scheme":    "Basic dXNlcjpwYXNz",
+		"expired":         "Bearer " + expired,
+		"other secret":    "Bearer " + otherSecret,
+		"other algorithm": "Bearer " + otherAlgorithm,
+		"without expiry":  "Bearer " + withoutExpiry,
+		"unknown role":    "Bearer " + unknownRole,
+	}
+	for name, header := range headers {
+		t.Run(name, func(t *testing.T) {
+			if _, err := authenticator.ParseAuthorization(header); !errors.Is(err, ErrUnauthenticated) {
+				t.Errorf("Expected ErrUnauthenticated, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthenticator_IssueRequiresCustomerForCustomerRole(t *testing.T) {
+	if _, err := NewAuthenticator("secret").Issue("", RoleCustomer, time.Hour); err == nil {
+		t.Error("Expected error for customer token without customer_id")
+	}
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"trabalho-03/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	RoleCustomer = "customer"
+	RoleAdmin    = "admin"
+)
+
+var (
+	ErrUnauthenticated = &domain.Error{Kind: domain.KindUnauthenticated, Message: "authentication required"}
+	ErrForbidden       = &domain.Error{Kind: domain.KindForbidden, Message: "not allowed to access this resource"}
+)
+
+// Claims identifies the caller. Customers may only see and change their own
+// orders; admins may access every customer's orders.
+type Claims struct {
+	CustomerID string `json:"customer_id,omitempty"`
+	Role       string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+func (c *Claims) IsAdmin() bool {
+	return c.Role == RoleAdmin
+}
+
+// CanAccess reports whether the caller may act on orders of customerID.
+func (c *Claims) CanAccess(customerID string) bool {
+	return c.IsAdmin() || (c.Role == RoleCustomer && c.CustomerID == customerID)
+}
+
+type claimsKey struct{}
+
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
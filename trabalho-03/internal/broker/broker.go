@@ -0,0 +1,16 @@
+package broker
+
+import "context"
+
+// Message is what the outbox relay hands to a broker. ID is stable across
+// redeliveries so consumers can deduplicate.
+type Message struct {
+	ID      string
+	Topic   string
+	Payload []byte
+}
+
+type Broker interface {
+	Publish(ctx context.Context, msg Message) error
+	Close() error
+}
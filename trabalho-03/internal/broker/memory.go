@@ -0,0 +1,37 @@
+package broker
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBroker keeps published messages in memory, used for local runs and tests.
+type MemoryBroker struct {
+	mutex    sync.RWMutex
+	messages []Message
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, msg Message) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.messages = append(b.messages, msg)
+	return nil
+}
+
+func (b *MemoryBroker) Messages() []Message {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	messages := make([]Message, len(b.messages))
+	copy(messages, b.messages)
+	return messages
+}
+
+func (b *MemoryBroker) Close() error {
+	return nil
+}
@@ -0,0 +1,165 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// RabbitMQBroker publishes to a durable topic exchange using the message topic
+// as routing key. Publisher confirms are enabled so Publish only succeeds once
+// the broker has taken responsibility for the message.
+//
+// When the connection or its channel closes (a broker restart, a network
+// failure), the next Publish dials again and reopens the confirm-mode channel;
+// until it succeeds Publish fails and the outbox keeps the messages.
+type RabbitMQBroker struct {
+	url      string
+	exchange string
+	dial     func(url, exchange string) (session, error)
+	session  session
+	mutex    sync.Mutex
+}
+
+// session is one connection with its confirm-mode channel. Done is closed once
+// either of them is closed, after which the session cannot publish anymore.
+type session interface {
+	Publish(ctx context.Context, exchange string, msg Message) error
+	Done() <-chan struct{}
+	Close() error
+}
+
+func NewRabbitMQBroker(url, exchange string) (*RabbitMQBroker, error) {
+	return newRabbitMQBroker(url, exchange, dialSession)
+}
+
+func newRabbitMQBroker(url, exchange string, dial func(url, exchange string) (session, error)) (*RabbitMQBroker, error) {
+	s, err := dial(url, exchange)
+	if err != nil {
+		return nil, err
+	}
+	return &RabbitMQBroker{url: url, exchange: exchange, dial: dial, session: s}, nil
+}
+
+func (b *RabbitMQBroker) Publish(ctx context.Context, msg Message) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.session == nil || isDone(b.session) {
+		if b.session != nil {
+			b.session.Close()
+			b.session = nil
+		}
+		s, err := b.dial(b.url, b.exchange)
+		if err != nil {
+			return fmt.Errorf("failed to reconnect to RabbitMQ: %w", err)
+		}
+		b.session = s
+	}
+
+	return b.session.Publish(ctx, b.exchange, msg)
+}
+
+func (b *RabbitMQBroker) Close() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.session == nil {
+		return nil
+	}
+	err := b.session.Close()
+	b.session = nil
+	return err
+}
+
+func isDone(s session) bool {
+	select {
+	case <-s.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+type amqpSession struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	done    chan struct{}
+}
+
+// dialSession connects, declares the exchange and enables publisher confirms.
+func dialSession(url, exchange string) (session, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open RabbitMQ channel: %w", err)
+	}
+
+	if err := channel.ExchangeDeclare(exchange, amqp.ExchangeTopic, true, false, false, false, nil); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
+	}
+
+	if err := channel.Confirm(false); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	s := &amqpSession{conn: conn, channel: channel, done: make(chan struct{})}
+	connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+	channelClosed := channel.NotifyClose(make(chan *amqp.Error, 1))
+	go func() {
+		var reason *amqp.Error
+		select {
+		case reason = <-connClosed:
+		case reason = <-channelClosed:
+		}
+		// A nil reason is a Close call of ours; anything else is unexpected.
+		if reason != nil {
+			log.Println("RabbitMQ connection closed, reconnecting on the next publish:", reason)
+		}
+		close(s.done)
+	}()
+
+	return s, nil
+}
+
+func (s *amqpSession) Publish(ctx context.Context, exchange string, msg Message) error {
+	confirmation, err := s.channel.PublishWithDeferredConfirmWithContext(ctx, exchange, msg.Topic, false, false, amqp.Publishing{
+		MessageId:    msg.ID,
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Type:         msg.Topic,
+		Body:         msg.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish message %s: %w", msg.ID, err)
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to confirm message %s: %w", msg.ID, err)
+	}
+	if !acked {
+		return fmt.Errorf("message %s was not acknowledged by the broker", msg.ID)
+	}
+
+	return nil
+}
+
+func (s *amqpSession) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *amqpSession) Close() error {
+	s.channel.Close()
+	return s.conn.Close()
+}
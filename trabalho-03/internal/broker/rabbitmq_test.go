@@ -0,0 +1,77 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeSession stands in for a RabbitMQ connection; closing done simulates the
+// broker dropping it.
+type fakeSession struct {
+	published []Message
+	done      chan struct{}
+	closed    bool
+}
+
+func (s *fakeSession) Publish(ctx context.Context, exchange string, msg Message) error {
+	s.published = append(s.published, msg)
+	return nil
+}
+
+func (s *fakeSession) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *fakeSession) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestRabbitMQBroker_ReconnectsAfterClose(t *testing.T) {
+	var sessions []*fakeSession
+	var dialErr error
+	dial := func(url, exchange string) (session, error) {
+		if dialErr != nil {
+			return nil, dialErr
+		}
+		s := &fakeSession{done: make(chan struct{})}
+		sessions = append(sessions, s)
+		return s, nil
+	}
+
+	b, err := newRabbitMQBroker("amqp://test", "orders", dial)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ctx := context.Background()
+	if err := b.Publish(ctx, Message{ID: "1"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The connection drops and RabbitMQ is still down on the next publish.
+	close(sessions[0].done)
+	dialErr = errors.New("connection refused")
+	if err := b.Publish(ctx, Message{ID: "2"}); err == nil {
+		t.Fatal("Expected an error while RabbitMQ is down")
+	}
+	if !sessions[0].closed {
+		t.Error("Expected the dropped session to be closed")
+	}
+
+	// Once it is back, the next publish reconnects.
+	dialErr = nil
+	if err := b.Publish(ctx, Message{ID: "3"}); err != nil {
+		t.Fatalf("Expected the broker to reconnect, got %v", err)
+	}
+	if len(sessions) != 2 || len(sessions[1].published) != 1 || sessions[1].published[0].ID != "3" {
+		t.Fatalf("Expected message 3 on a new session, got %d sessions", len(sessions))
+	}
+	if len(sessions[0].published) != 1 {
+		t.Errorf("Expected only message 1 on the dropped session, got %d", len(sessions[0].published))
+	}
+
+	if err := b.Close(); err != nil || !sessions[1].closed {
+		t.Errorf("Expected Close to close the session, got %v", err)
+	}
+}
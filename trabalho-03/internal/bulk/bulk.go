@@ -0,0 +1,57 @@
+// Package bulk converts orders to and from the CSV and JSON Lines files used
+// by bulk import and export.
+package bulk
+
+import (
+	"fmt"
+	"io"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/usecase"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// ContentTypes maps each format to the media type used in HTTP.
+var ContentTypes = map[string]string{
+	FormatCSV:   "text/csv",
+	FormatJSONL: "application/x-ndjson",
+}
+
+// Decoder reads one record at a time. Records that cannot be parsed are
+// returned as rows with Err set; Next only fails when the input as a whole
+// cannot be read any further, and returns io.EOF at its end.
+type Decoder interface {
+	Next() (usecase.ImportRow, error)
+}
+
+// Encoder writes orders as they are produced. Flush must be called once at the
+// end so buffered output (and the CSV header of an empty export) is written.
+type Encoder interface {
+	Encode(orders []domain.Order) error
+	Flush() error
+}
+
+// NewDecoder returns a decoder for format. CSV input must start with a header
+// naming at least the customer_id, status and items columns.
+func NewDecoder(format string, r io.Reader) (Decoder, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVDecoder(r)
+	case FormatJSONL:
+		return newJSONLDecoder(r), nil
+	}
+	return nil, fmt.Errorf("unsupported format %q, use %s or %s", format, FormatCSV, FormatJSONL)
+}
+
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVEncoder(w), nil
+	case FormatJSONL:
+		return newJSONLEncoder(w), nil
+	}
+	return nil, fmt.Errorf("unsupported format %q, use %s or %s", format, FormatCSV, FormatJSONL)
+}
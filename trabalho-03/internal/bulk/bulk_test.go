@@ -0,0 +1,167 @@
+package bulk
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/usecase"
+	"trabalho-03/internal/usecase/validation"
+)
+
+func readAll(t *testing.T, decoder Decoder) []usecase.ImportRow {
+	t.Helper()
+	var rows []usecase.ImportRow
+	for {
+		row, err := decoder.Next()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVDecoder_ReportsRowErrorsWithLineNumbers(t *testing.T) {
+	input := strings.Join([]string{
+		"customer_id,status,items",
+		"customer-1,pending,product-1:2:5025;sku:with:colons:1:399",
+		"customer-2,pending,product-1:two:5025",
+		"customer-3,pending",
+		"",
+		"customer-4,confirmed,product-9",
+	}, "\n")
+	decoder, err := NewDecoder(FormatCSV, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	rows := readAll(t, decoder)
+
+	if len(rows) != 4 {
+		t.Fatalf("Expected 4 rows, got %d", len(rows))
+	}
+	want := &domain.Order{
+		CustomerID: "customer-1",
+		Status:     domain.OrderStatusPending,
+		Items: []domain.OrderItem{
+			{ProductID: "product-1", Quantity: 2, UnitPriceCents: 5025},
+			{ProductID: "sku:with:colons", Quantity: 1, UnitPriceCents: 399},
+		},
+	}
+	if rows[0].Line != 2 || rows[0].Err != nil || !reflect.DeepEqual(rows[0].Order, want) {
+		t.Errorf("Expected line 2 to be %+v, got %+v", want, rows[0])
+	}
+
+	var fieldErrs validation.Errors
+	if rows[1].Line != 3 || !errors.As(rows[1].Err, &fieldErrs) || fieldErrs[0].Field != "items[0].quantity" {
+		t.Errorf("Expected an items[0].quantity error on line 3, got %+v", rows[1])
+	}
+	if rows[2].Line != 4 || rows[2].Err == nil {
+		t.Errorf("Expected a field count error on line 4, got %+v", rows[2])
+	}
+	if rows[3].Line != 6 || !errors.As(rows[3].Err, &fieldErrs) || fieldErrs[0].Field != "items[0]" {
+		t.Errorf("Expected an items[0] error on line 6, got %+v", rows[3])
+	}
+}
+
+func TestCSVDecoder_RequiresHeaderColumns(t *testing.T) {
+	for _, input := range []string{"", "customer_id,status\n"} {
+		if _, err := NewDecoder(FormatCSV, strings.NewReader(input)); err == nil {
+			t.Errorf("Expected an error for header %q", input)
+		}
+	}
+}
+
+func TestJSONLDecoder_ReportsRowErrorsWithLineNumbers(t *testing.T) {
+	input := `{"customer_id": "customer-1", "status": "pending", "items": [{"product_id": "p", "quantity": 1, "unit_price_cents": 10}]}
+
+{"customer_id": "customer-2",
+{"customer_id": "customer-3", "status": "pending", "items": []}
+`
+	rows := readAll(t, newJSONLDecoder(strings.NewReader(input)))
+
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(rows))
+	}
+	if rows[0].Line != 1 || rows[0].Err != nil || len(rows[0].Order.Items) != 1 {
+		t.Errorf("Expected a valid order on line 1, got %+v", rows[0])
+	}
+	if rows[1].Line != 3 || rows[1].Err == nil {
+		t.Errorf("Expected invalid JSON on line 3, got %+v", rows[1])
+	}
+	if rows[2].Line != 4 || rows[2].Err != nil || rows[2].Order.CustomerID != "customer-3" {
+		t.Errorf("Expected customer-3 on line 4, got %+v", rows[2])
+	}
+}
+
+func TestExportCanBeImportedBack(t *testing.T) {
+	orders := []domain.Order{
+		{
+			ID:          1,
+			CustomerID:  "customer-1",
+			Status:      domain.OrderStatusPending,
+			AmountCents: 10449,
+			Items: []domain.OrderItem{
+				{ProductID: "product-1", Quantity: 2, UnitPriceCents: 5025},
+				{ProductID: "product-2", Quantity: 1, UnitPriceCents: 399},
+			},
+			CreatedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC),
+		},
+		{ID: 2, CustomerID: "customer-2, Inc.", Status: domain.OrderStatusShipped, AmountCents: 100,
+			Items: []domain.OrderItem{{ProductID: "product-3", Quantity: 1, UnitPriceCents: 100}}},
+	}
+
+	for _, format := range []string{FormatCSV, FormatJSONL} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			encoder, err := NewEncoder(format, &buf)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for i := range orders {
+				if err := encoder.Encode(orders[i : i+1]); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+			if err := encoder.Flush(); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			decoder, err := NewDecoder(format, &buf)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			rows := readAll(t, decoder)
+			if len(rows) != len(orders) {
+				t.Fatalf("Expected %d rows, got %d", len(orders), len(rows))
+			}
+			for i, row := range rows {
+				want := &domain.Order{CustomerID: orders[i].CustomerID, Status: orders[i].Status, Items: orders[i].Items}
+				for j := range want.Items {
+					want.Items[j].ID, want.Items[j].OrderID = 0, 0
+				}
+				if row.Err != nil || !reflect.DeepEqual(row.Order, want) {
+					t.Errorf("Expected %+v, got %+v (err %v)", want, row.Order, row.Err)
+				}
+			}
+		})
+	}
+}
+
+func TestCSVEncoder_WritesHeaderForEmptyExport(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := newCSVEncoder(&buf)
+	if err := encoder.Flush(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := buf.String(); got != strings.Join(csvColumns, ",")+"\n" {
+		t.Errorf("Expected only the header, got %q", got)
+	}
+}
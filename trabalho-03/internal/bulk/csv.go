@@ -0,0 +1,173 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/usecase"
+	"trabalho-03/internal/usecase/validation"
+)
+
+// csvColumns are written on export. Import only reads customer_id, status and
+// items, so an export can be imported back as new orders.
+var csvColumns = []string{"id", "customer_id", "status", "amount_cents", "items", "created_at", "updated_at"}
+
+var requiredCSVColumns = []string{"customer_id", "status", "items"}
+
+// In the items column each item is written as product_id:quantity:unit_price_cents
+// and items are separated by semicolons, e.g. "product-1:2:5025;product-2:1:399".
+const (
+	itemSeparator      = ";"
+	itemFieldSeparator = ":"
+)
+
+type csvDecoder struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVDecoder(r io.Reader) (*csvDecoder, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("missing CSV header")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredCSVColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header must include the %s column", name)
+		}
+	}
+	return &csvDecoder{reader: reader, columns: columns}, nil
+}
+
+func (d *csvDecoder) Next() (usecase.ImportRow, error) {
+	record, err := d.reader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return usecase.ImportRow{Line: parseErr.StartLine, Err: parseErr.Err}, nil
+	}
+	if err != nil {
+		return usecase.ImportRow{}, err
+	}
+	line, _ := d.reader.FieldPos(0)
+
+	items, err := parseItems(record[d.columns["items"]])
+	if err != nil {
+		return usecase.ImportRow{Line: line, Err: err}, nil
+	}
+	order := &domain.Order{
+		CustomerID: record[d.columns["customer_id"]],
+		Status:     record[d.columns["status"]],
+		Items:      items,
+	}
+	return usecase.ImportRow{Line: line, Order: order}, nil
+}
+
+func parseItems(value string) ([]domain.OrderItem, error) {
+	var items []domain.OrderItem
+	var errs validation.Errors
+	for i, field := range strings.Split(value, itemSeparator) {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		// Product IDs may contain the field separator; the numbers never do.
+		priceAt := strings.LastIndex(field, itemFieldSeparator)
+		quantityAt := strings.LastIndex(field[:max(priceAt, 0)], itemFieldSeparator)
+		if quantityAt < 0 {
+			errs = append(errs, validation.FieldError{
+				Field:   fmt.Sprintf("items[%d]", i),
+				Message: "must be written as product_id:quantity:unit_price_cents",
+			})
+			continue
+		}
+		quantity, err := strconv.ParseInt(field[quantityAt+1:priceAt], 10, 32)
+		if err != nil {
+			errs = append(errs, validation.FieldError{Field: fmt.Sprintf("items[%d].quantity", i), Message: "must be an integer"})
+		}
+		unitPrice, err := strconv.ParseInt(field[priceAt+1:], 10, 64)
+		if err != nil {
+			errs = append(errs, validation.FieldError{Field: fmt.Sprintf("items[%d].unit_price_cents", i), Message: "must be an integer"})
+		}
+		items = append(items, domain.OrderItem{
+			ProductID:      field[:quantityAt],
+			Quantity:       int32(quantity),
+			UnitPriceCents: unitPrice,
+		})
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return items, nil
+}
+
+func formatItems(items []domain.OrderItem) string {
+	fields := make([]string, 0, len(items))
+	for _, item := range items {
+		fields = append(fields, fmt.Sprintf("%s%s%d%s%d",
+			item.ProductID, itemFieldSeparator, item.Quantity, itemFieldSeparator, item.UnitPriceCents))
+	}
+	return strings.Join(fields, itemSeparator)
+}
+
+type csvEncoder struct {
+	writer      *csv.Writer
+	wroteHeader bool
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	return &csvEncoder{writer: csv.NewWriter(w)}
+}
+
+func (e *csvEncoder) writeHeader() error {
+	if e.wroteHeader {
+		return nil
+	}
+	e.wroteHeader = true
+	return e.writer.Write(csvColumns)
+}
+
+func (e *csvEncoder) Encode(orders []domain.Order) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	for _, order := range orders {
+		err := e.writer.Write([]string{
+			strconv.FormatUint(uint64(order.ID), 10),
+			order.CustomerID,
+			order.Status,
+			strconv.FormatInt(order.AmountCents, 10),
+			formatItems(order.Items),
+			order.CreatedAt.UTC().Format(time.RFC3339),
+			order.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvEncoder) Flush() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
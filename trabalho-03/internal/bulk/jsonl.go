@@ -0,0 +1,88 @@
+package bulk
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/usecase"
+)
+
+// maxJSONLLineSize bounds the memory used by a single JSON Lines record.
+const maxJSONLLineSize = 1 << 20
+
+// jsonlOrder has the same fields as the REST create request; the extra fields
+// of exported orders are ignored so an export can be imported back.
+type jsonlOrder struct {
+	CustomerID string `json:"customer_id"`
+	Status     string `json:"status"`
+	Items      []struct {
+		ProductID      string `json:"product_id"`
+		Quantity       int32  `json:"quantity"`
+		UnitPriceCents int64  `json:"unit_price_cents"`
+	} `json:"items"`
+}
+
+type jsonlDecoder struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newJSONLDecoder(r io.Reader) *jsonlDecoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLLineSize)
+	return &jsonlDecoder{scanner: scanner}
+}
+
+func (d *jsonlDecoder) Next() (usecase.ImportRow, error) {
+	for d.scanner.Scan() {
+		d.line++
+		line := d.scanner.Bytes()
+		if strings.TrimSpace(string(line)) == "" {
+			continue
+		}
+
+		var record jsonlOrder
+		if err := json.Unmarshal(line, &record); err != nil {
+			return usecase.ImportRow{Line: d.line, Err: fmt.Errorf("invalid JSON: %w", err)}, nil
+		}
+		order := &domain.Order{CustomerID: record.CustomerID, Status: record.Status}
+		for _, item := range record.Items {
+			order.Items = append(order.Items, domain.OrderItem{
+				ProductID:      item.ProductID,
+				Quantity:       item.Quantity,
+				UnitPriceCents: item.UnitPriceCents,
+			})
+		}
+		return usecase.ImportRow{Line: d.line, Order: order}, nil
+	}
+	if err := d.scanner.Err(); err != nil {
+		return usecase.ImportRow{}, fmt.Errorf("line %d: %w", d.line+1, err)
+	}
+	return usecase.ImportRow{}, io.EOF
+}
+
+type jsonlEncoder struct {
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newJSONLEncoder(w io.Writer) *jsonlEncoder {
+	writer := bufio.NewWriter(w)
+	return &jsonlEncoder{writer: writer, encoder: json.NewEncoder(writer)}
+}
+
+func (e *jsonlEncoder) Encode(orders []domain.Order) error {
+	for i := range orders {
+		if err := e.encoder.Encode(&orders[i]); err != nil {
+			return err
+		}
+	}
+	return e.writer.Flush()
+}
+
+func (e *jsonlEncoder) Flush() error {
+	return e.writer.Flush()
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Customer is the owner of orders; Order.CustomerID refers to Customer.ID.
+type Customer struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
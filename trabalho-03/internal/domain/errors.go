@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Kind classifies an error independently of the transport. Each transport maps
+// kinds to its own status codes; the values double as the machine-readable
+// code of REST problem details and GraphQL error extensions.
+type Kind string
+
+const (
+	KindInvalid         Kind = "BAD_USER_INPUT"
+	KindNotFound        Kind = "NOT_FOUND"
+	KindConflict        Kind = "CONFLICT"
+	KindPrecondition    Kind = "PRECONDITION_FAILED"
+	KindUnauthenticated Kind = "UNAUTHENTICATED"
+	KindForbidden       Kind = "FORBIDDEN"
+	KindTimeout         Kind = "DEADLINE_EXCEEDED"
+	KindCanceled        Kind = "CANCELED"
+	KindInternal        Kind = "INTERNAL_SERVER_ERROR"
+)
+
+// internalMessage replaces the message of unclassified errors, which may
+// reveal implementation details.
+const internalMessage = "internal server error"
+
+// Error is an error of a known Kind whose message is safe to show to clients.
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+}
+
+// Errorf builds an Error like fmt.Errorf, keeping a %w operand as the cause.
+func Errorf(kind Kind, format string, args ...any) *Error {
+	err := fmt.Errorf(format, args...)
+	return &Error{Kind: kind, Message: err.Error(), Err: errors.Unwrap(err)}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) ErrorKind() Kind {
+	return e.Kind
+}
+
+// KindOf returns the kind of the first error in err's chain that declares one
+// through an ErrorKind method. Context errors are KindTimeout or KindCanceled
+// and any other error is KindInternal.
+func KindOf(err error) Kind {
+	var kinded interface{ ErrorKind() Kind }
+	switch {
+	case errors.As(err, &kinded):
+		return kinded.ErrorKind()
+	case errors.Is(err, context.DeadlineExceeded):
+		return KindTimeout
+	case errors.Is(err, context.Canceled):
+		return KindCanceled
+	}
+	return KindInternal
+}
+
+// Describe returns the kind of err and a message that can be shown to clients.
+func Describe(err error) (Kind, string) {
+	switch kind := KindOf(err); kind {
+	case KindTimeout:
+		return kind, "request timed out"
+	case KindCanceled:
+		return kind, "request canceled"
+	case KindInternal:
+		return kind, internalMessage
+	default:
+		return kind, err.Error()
+	}
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	notFound := &Error{Kind: KindNotFound, Message: "order not found"}
+
+	tests := map[string]struct {
+		err         error
+		wantKind    Kind
+		wantMessage string
+	}{
+		"domain error":       {notFound, KindNotFound, "order not found"},
+		"wrapped":            {fmt.Errorf("loading: %w", notFound), KindNotFound, "loading: order not found"},
+		"errorf with cause":  {Errorf(KindInvalid, "bad input: %w", errors.New("eof")), KindInvalid, "bad input: eof"},
+		"deadline":           {fmt.Errorf("query: %w", context.DeadlineExceeded), KindTimeout, "request timed out"},
+		"canceled":           {context.Canceled, KindCanceled, "request canceled"},
+		"unclassified error": {errors.New("pq: relation \"orders\" does not exist"), KindInternal, "internal server error"},
+	}
+	for name, tt := range tests {
+		kind, message := Describe(tt.err)
+		if kind != tt.wantKind || message != tt.wantMessage {
+			t.Errorf("%s: Describe() = %s, %q; want %s, %q", name, kind, message, tt.wantKind, tt.wantMessage)
+		}
+	}
+}
+
+func TestErrorf_KeepsCause(t *testing.T) {
+	cause := errors.New("eof")
+	if err := Errorf(KindInvalid, "bad input: %w", cause); !errors.Is(err, cause) {
+		t.Errorf("Expected %v to wrap its cause", err)
+	}
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderStatusChanged = "order.status_changed"
+	EventOrderDeleted       = "order.deleted"
+	EventOrderRestored      = "order.restored"
+)
+
+type OrderCreated struct {
+	OrderID     uint      `json:"order_id"`
+	CustomerID  string    `json:"customer_id"`
+	AmountCents int64     `json:"amount_cents"`
+	Status      string    `json:"status"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+type OrderStatusChanged struct {
+	OrderID    uint      `json:"order_id"`
+	CustomerID string    `json:"customer_id"`
+	OldStatus  string    `json:"old_status"`
+	NewStatus  string    `json:"new_status"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// OrderDeleted is recorded when an order is soft-deleted. It is purged later,
+// without a further event.
+type OrderDeleted struct {
+	OrderID    uint      `json:"order_id"`
+	CustomerID string    `json:"customer_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+type OrderRestored struct {
+	OrderID    uint      `json:"order_id"`
+	CustomerID string    `json:"customer_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
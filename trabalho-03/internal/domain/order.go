@@ -1,17 +1,100 @@
 package domain
 
 import (
+	"math"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// Order amounts are stored as integer cents to avoid float rounding. Version
+// starts at 1 and grows with every update, so clients can detect concurrent
+// edits.
 type Order struct {
-	ID         uint           `json:"id" gorm:"primaryKey"`
-	CustomerID string         `json:"customer_id"`
-	Amount     float64        `json:"amount"`
-	Status     string         `json:"status"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	CustomerID  string         `json:"customer_id"`
+	Items       []OrderItem    `json:"items" gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
+	AmountCents int64          `json:"amount_cents"`
+	Status      string         `json:"status"`
+	Version     uint           `json:"version" gorm:"not null;default:1"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+type OrderItem struct {
+	ID             uint   `json:"id" gorm:"primaryKey"`
+	OrderID        uint   `json:"order_id" gorm:"index;not null"`
+	ProductID      string `json:"product_id" gorm:"not null"`
+	Quantity       int32  `json:"quantity" gorm:"not null"`
+	UnitPriceCents int64  `json:"unit_price_cents" gorm:"not null"`
+}
+
+// Upper bounds on what a client may order, far below int64 overflow: one item
+// totals at most 10^16 cents, and so does the order.
+const (
+	MaxItemQuantity     = 1_000_000
+	MaxUnitPriceCents   = 10_000_000_000
+	MaxOrderAmountCents = 10_000_000_000_000_000
+)
+
+// TotalCents is Quantity times UnitPriceCents, saturated at math.MaxInt64 (or
+// math.MinInt64) instead of wrapping around.
+func (i OrderItem) TotalCents() int64 {
+	quantity := int64(i.Quantity)
+	if quantity != 0 && i.UnitPriceCents != 0 {
+		if total := quantity * i.UnitPriceCents; total/quantity != i.UnitPriceCents {
+			if (quantity > 0) == (i.UnitPriceCents > 0) {
+				return math.MaxInt64
+			}
+			return math.MinInt64
+		}
+	}
+	return quantity * i.UnitPriceCents
+}
+
+// CalculateAmount sets AmountCents from the order items, ignoring any value sent
+// by the client. Like TotalCents it saturates instead of overflowing, so an
+// oversized order fails validation rather than wrapping to a small amount.
+func (o *Order) CalculateAmount() {
+	var total int64
+	for _, item := range o.Items {
+		itemTotal := item.TotalCents()
+		switch {
+		case itemTotal > 0 && total > math.MaxInt64-itemTotal:
+			total = math.MaxInt64
+		case itemTotal < 0 && total < math.MinInt64-itemTotal:
+			total = math.MinInt64
+		default:
+			total += itemTotal
+		}
+	}
+	o.AmountCents = total
+}
+
+const (
+	OrderStatusPending    = "pending"
+	OrderStatusConfirmed  = "confirmed"
+	OrderStatusProcessing = "processing"
+	OrderStatusShipped    = "shipped"
+	OrderStatusDelivered  = "delivered"
+	OrderStatusCancelled  = "cancelled"
+)
+
+var OrderStatuses = []string{
+	OrderStatusPending,
+	OrderStatusConfirmed,
+	OrderStatusProcessing,
+	OrderStatusShipped,
+	OrderStatusDelivered,
+	OrderStatusCancelled,
+}
+
+func IsValidOrderStatus(status string) bool {
+	for _, s := range OrderStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOrder_CalculateAmountSaturates(t *testing.T) {
+	tests := map[string]struct {
+		items []OrderItem
+		want  int64
+	}{
+		"items":             {[]OrderItem{{Quantity: 2, UnitPriceCents: 500}, {Quantity: 1, UnitPriceCents: 99}}, 1099},
+		"item overflow":     {[]OrderItem{{Quantity: math.MaxInt32, UnitPriceCents: math.MaxInt64 / 2}}, math.MaxInt64},
+		"negative overflow": {[]OrderItem{{Quantity: math.MaxInt32, UnitPriceCents: math.MinInt64 / 2}}, math.MinInt64},
+		"sum overflow":      {[]OrderItem{{Quantity: 1, UnitPriceCents: math.MaxInt64}, {Quantity: 1, UnitPriceCents: 1}}, math.MaxInt64},
+	}
+	for name, tt := range tests {
+		order := Order{Items: tt.items}
+		order.CalculateAmount()
+		if order.AmountCents != tt.want {
+			t.Errorf("%s: AmountCents = %d; want %d", name, order.AmountCents, tt.want)
+		}
+	}
+}
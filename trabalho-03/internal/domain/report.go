@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Groupings accepted by order reports.
+const (
+	ReportGroupByCustomer = "customer"
+	ReportGroupByStatus   = "status"
+	ReportGroupByDay      = "day"
+)
+
+var ReportGroupings = []string{ReportGroupByCustomer, ReportGroupByStatus, ReportGroupByDay}
+
+// ReportDayLayout formats the keys of day reports; days are taken in UTC.
+const ReportDayLayout = "2006-01-02"
+
+// ReportFilter selects the orders aggregated by a report. From is inclusive, To
+// is exclusive and a zero value leaves that side open. An empty CustomerID
+// includes every customer.
+type ReportFilter struct {
+	GroupBy    string
+	From       time.Time
+	To         time.Time
+	CustomerID string
+}
+
+// OrderStats aggregates the orders sharing Key: a customer ID, a status or a
+// day formatted with ReportDayLayout.
+type OrderStats struct {
+	Key                string  `json:"key"`
+	Count              int64   `json:"count"`
+	TotalAmountCents   int64   `json:"total_amount_cents"`
+	AverageAmountCents float64 `json:"average_amount_cents"`
+}
+
+// ParseReportTime parses a report bound given as an RFC 3339 timestamp or a
+// plain date, returning the zero time for an empty value. Plain dates are taken
+// in UTC; with endOfDay they move to the start of the next day so that an
+// exclusive To bound still includes the whole date.
+func ParseReportTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(ReportDayLayout, value)
+	if err != nil {
+		return time.Time{}, errors.New("must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,80 @@
+package event
+
+import (
+	"sync"
+	"trabalho-03/internal/domain"
+)
+
+// OrderEvent carries the order state right after the change described by Type.
+type OrderEvent struct {
+	Type  string
+	Order domain.Order
+}
+
+// Bus is an in-process fan-out of order events. Delivery is best effort:
+// subscribers that cannot keep up miss events instead of blocking publishers.
+type Bus struct {
+	mutex       sync.RWMutex
+	subscribers map[int]chan OrderEvent
+	nextID      int
+	closed      bool
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[int]chan OrderEvent)}
+}
+
+func (b *Bus) Publish(evt OrderEvent) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- evt:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel of events and a function that must be called to
+// release it. The channel is closed when the bus is closed.
+func (b *Bus) Subscribe(buffer int) (<-chan OrderEvent, func()) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		ch := make(chan OrderEvent)
+		close(ch)
+		return ch, func() {}
+	}
+
+	id := b.nextID
+	b.nextID++
+	ch := make(chan OrderEvent, buffer)
+	b.subscribers[id] = ch
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mutex.Lock()
+			defer b.mutex.Unlock()
+
+			if _, exists := b.subscribers[id]; exists {
+				delete(b.subscribers, id)
+				close(ch)
+			}
+		})
+	}
+}
+
+// Close ends every subscription, letting long-lived subscribers finish on shutdown.
+func (b *Bus) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.closed = true
+	for id, ch := range b.subscribers {
+		delete(b.subscribers, id)
+		close(ch)
+	}
+}
@@ -0,0 +1,126 @@
+package grpc
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/usecase/validation"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var kindCodes = map[domain.Kind]codes.Code{
+	domain.KindInvalid:         codes.InvalidArgument,
+	domain.KindNotFound:        codes.NotFound,
+	domain.KindConflict:        codes.AlreadyExists,
+	domain.KindPrecondition:    codes.Aborted,
+	domain.KindUnauthenticated: codes.Unauthenticated,
+	domain.KindForbidden:       codes.PermissionDenied,
+	domain.KindTimeout:         codes.DeadlineExceeded,
+	domain.KindCanceled:        codes.Canceled,
+	domain.KindInternal:        codes.Internal,
+}
+
+// codeStatus follows the HTTP mapping of grpc-gateway.
+var codeStatus = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           statusClientClosedRequest,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+}
+
+// statusClientClosedRequest is the non-standard status logged when the client
+// disconnects before the response is written.
+const statusClientClosedRequest = 499
+
+// toStatus returns the status a gRPC client receives for err. Status errors are
+// kept; other errors are mapped by domain kind, with validation failures
+// attached as BadRequest field violations.
+func toStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	if s, ok := status.FromError(err); ok {
+		return s
+	}
+
+	kind, message := domain.Describe(err)
+	s := status.New(kindCodes[kind], message)
+	var validationErrs validation.Errors
+	if errors.As(err, &validationErrs) {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: fe.Field, Description: fe.Message})
+		}
+		if detailed, err := s.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+			s = detailed
+		}
+	}
+	return s
+}
+
+// codeOf returns the gRPC code a real server would send for err.
+func codeOf(err error) codes.Code {
+	return toStatus(err).Code()
+}
+
+// statusError converts the errors returned by OrderService methods into status
+// errors, as a real gRPC service must, logging internal failures since their
+// message does not reach the client.
+func statusError(err error) error {
+	s := toStatus(err)
+	if s.Code() == codes.Internal {
+		log.Printf("OrderService call failed: %v", err)
+	}
+	return s.Err()
+}
+
+// statusBody is google.rpc.Status as serialized by grpc-gateway, with the code
+// spelled out and the field violations flattened into details.
+type statusBody struct {
+	Code    string                  `json:"code"`
+	Message string                  `json:"message"`
+	Details []validation.FieldError `json:"details,omitempty"`
+}
+
+// writeStatus answers a failed call with its status in the grpc-status and
+// grpc-message headers and in the body. Calls whose client went away get no
+// body.
+func writeStatus(c *gin.Context, err error) {
+	s := toStatus(err)
+	httpStatus, ok := codeStatus[s.Code()]
+	if !ok {
+		httpStatus = http.StatusInternalServerError
+	}
+	if s.Code() == codes.Canceled {
+		c.AbortWithStatus(httpStatus)
+		return
+	}
+
+	body := statusBody{Code: s.Code().String(), Message: s.Message()}
+	for _, detail := range s.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				body.Details = append(body.Details, validation.FieldError{Field: violation.GetField(), Message: violation.GetDescription()})
+			}
+		}
+	}
+	c.Header("grpc-status", strconv.Itoa(int(s.Code())))
+	c.Header("grpc-message", s.Message())
+	c.AbortWithStatusJSON(httpStatus, body)
+}
@@ -0,0 +1,144 @@
+package grpc
+
+import (
+	"context"
+	"time"
+	"trabalho-03/internal/auth"
+	"trabalho-03/internal/telemetry"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthorizationMetadata carries the "Bearer <token>" credentials of the caller.
+const AuthorizationMetadata = "authorization"
+
+// AuthInterceptor authenticates the caller from the authorization metadata and
+// stores its claims in the context handed to the service. It has the standard
+// unary interceptor signature so it can be installed on a real gRPC server too.
+func AuthInterceptor(authenticator *auth.Authenticator) grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, authenticator)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamInterceptor is the streaming counterpart of AuthInterceptor.
+func AuthStreamInterceptor(authenticator *auth.Authenticator) grpclib.StreamServerInterceptor {
+	return func(srv any, ss grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), authenticator)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, authenticator *auth.Authenticator) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(AuthorizationMetadata)
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, auth.ErrUnauthenticated.Error())
+	}
+	claims, err := authenticator.ParseAuthorization(values[0])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return auth.WithClaims(ctx, claims), nil
+}
+
+// TimeoutInterceptor caps the deadline of every call at d. Shorter deadlines
+// requested by the client through grpc-timeout are kept.
+func TimeoutInterceptor(d time.Duration) grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// TimeoutStreamInterceptor is the streaming counterpart of TimeoutInterceptor.
+// Streams usually get a longer d than unary calls.
+func TimeoutStreamInterceptor(d time.Duration) grpclib.StreamServerInterceptor {
+	return func(srv any, ss grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+		ctx, cancel := context.WithTimeout(ss.Context(), d)
+		defer cancel()
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// TelemetryInterceptor traces every call, continuing the trace propagated in
+// the incoming metadata, and records RED metrics per method. Only codes that
+// point at a server-side problem count as errors.
+func TelemetryInterceptor() grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		ctx, request := telemetry.StartRequest(ctx, telemetry.TransportGRPC, info.FullMethod, metadataCarrier(md))
+
+		resp, err := handler(ctx, req)
+		endRequest(request, err)
+		return resp, err
+	}
+}
+
+// TelemetryStreamInterceptor is the streaming counterpart of TelemetryInterceptor;
+// a call is measured from its start until the response is sent.
+func TelemetryStreamInterceptor() grpclib.StreamServerInterceptor {
+	return func(srv any, ss grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+		md, _ := metadata.FromIncomingContext(ss.Context())
+		ctx, request := telemetry.StartRequest(ss.Context(), telemetry.TransportGRPC, info.FullMethod, metadataCarrier(md))
+
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		endRequest(request, err)
+		return err
+	}
+}
+
+func endRequest(request *telemetry.Request, err error) {
+	code := codeOf(err)
+	var serverErr error
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.Unimplemented, codes.DeadlineExceeded:
+		serverErr = err
+	}
+	request.End(code.String(), serverErr)
+}
+
+// metadataCarrier adapts gRPC metadata to the OpenTelemetry propagators.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// chainInterceptors runs the interceptors in order around handler, like
+// grpc.ChainUnaryInterceptor does.
+func chainInterceptors(interceptors []grpclib.UnaryServerInterceptor, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) grpclib.UnaryHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, req any) (any, error) {
+			return interceptor(ctx, req, info, next)
+		}
+	}
+	return handler
+}
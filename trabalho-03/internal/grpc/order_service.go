@@ -6,35 +6,127 @@ import (
 	"time"
 	"trabalho-03/internal/domain"
 	"trabalho-03/internal/usecase"
+	"trabalho-03/internal/usecase/validation"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
+// IdempotencyKeyMetadata is the metadata key clients set to make CreateOrder retries safe.
+const IdempotencyKeyMetadata = "idempotency-key"
+
 // Simple gRPC-like structs without protobuf dependency
+type OrderItemMessage struct {
+	ProductID      string `json:"product_id"`
+	Quantity       int32  `json:"quantity"`
+	UnitPriceCents int64  `json:"unit_price_cents"`
+}
+
 type OrderMessage struct {
-	ID         uint32  `json:"id"`
-	CustomerID string  `json:"customer_id"`
-	Amount     float64 `json:"amount"`
-	Status     string  `json:"status"`
-	CreatedAt  string  `json:"created_at"`
-	UpdatedAt  string  `json:"updated_at"`
+	ID          uint32              `json:"id"`
+	CustomerID  string              `json:"customer_id"`
+	AmountCents int64               `json:"amount_cents"`
+	Status      string              `json:"status"`
+	Version     uint32              `json:"version"`
+	CreatedAt   string              `json:"created_at"`
+	UpdatedAt   string              `json:"updated_at"`
+	DeletedAt   string              `json:"deleted_at,omitempty"`
+	Items       []*OrderItemMessage `json:"items"`
 }
 
 type CreateOrderRequest struct {
-	CustomerID string  `json:"customer_id"`
-	Amount     float64 `json:"amount"`
-	Status     string  `json:"status"`
+	CustomerID string              `json:"customer_id"`
+	Status     string              `json:"status"`
+	Items      []*OrderItemMessage `json:"items"`
 }
 
 type CreateOrderResponse struct {
 	Order *OrderMessage `json:"order"`
 }
 
-type ListOrdersRequest struct{}
+// UpdateOrderStatusRequest fails with Aborted when Version is set and the
+// order has changed since; zero skips the check.
+type UpdateOrderStatusRequest struct {
+	ID      uint32 `json:"id"`
+	Status  string `json:"status"`
+	Version uint32 `json:"version"`
+}
+
+type UpdateOrderStatusResponse struct {
+	Order *OrderMessage `json:"order"`
+}
+
+// DeleteOrderRequest soft-deletes an order; Version works as in
+// UpdateOrderStatusRequest.
+type DeleteOrderRequest struct {
+	ID      uint32 `json:"id"`
+	Version uint32 `json:"version"`
+}
+
+type DeleteOrderResponse struct {
+	Order *OrderMessage `json:"order"`
+}
+
+type RestoreOrderRequest struct {
+	ID      uint32 `json:"id"`
+	Version uint32 `json:"version"`
+}
+
+type RestoreOrderResponse struct {
+	Order *OrderMessage `json:"order"`
+}
+
+// ListOrdersRequest may set IncludeDeleted to list soft-deleted orders too;
+// only admins may do so.
+type ListOrdersRequest struct {
+	IncludeDeleted bool `json:"include_deleted"`
+}
 
 type ListOrdersResponse struct {
 	Orders []*OrderMessage `json:"orders"`
 }
 
-// OrderService implements a simplified gRPC-like service
+// ImportOrderError reports a message of an ImportOrders stream that was not
+// imported; Index is its 1-based position in the stream.
+type ImportOrderError struct {
+	Index  int32                   `json:"index"`
+	Error  string                  `json:"error"`
+	Fields []validation.FieldError `json:"fields,omitempty"`
+}
+
+type ImportOrdersResponse struct {
+	ImportedCount int32               `json:"imported_count"`
+	FailedCount   int32               `json:"failed_count"`
+	Errors        []*ImportOrderError `json:"errors"`
+}
+
+// ImportOrdersStream is the server side of the client-streaming ImportOrders
+// call: the client sends CreateOrderRequest messages and receives a single
+// ImportOrdersResponse once it closes its side.
+type ImportOrdersStream interface {
+	Recv() (*CreateOrderRequest, error)
+	SendAndClose(*ImportOrdersResponse) error
+	grpclib.ServerStream
+}
+
+type importOrdersStream struct {
+	grpclib.ServerStream
+}
+
+func (s *importOrdersStream) Recv() (*CreateOrderRequest, error) {
+	req := &CreateOrderRequest{}
+	if err := s.RecvMsg(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (s *importOrdersStream) SendAndClose(resp *ImportOrdersResponse) error {
+	return s.SendMsg(resp)
+}
+
+// OrderService implements a simplified gRPC-like service. Like a real gRPC
+// service, its methods fail with status errors.
 type OrderService struct {
 	orderUseCase *usecase.OrderUseCase
 }
@@ -46,48 +138,26 @@ func NewOrderService(orderUseCase *usecase.OrderUseCase) *OrderService {
 }
 
 func (s *OrderService) CreateOrder(ctx context.Context, req *CreateOrderRequest) (*CreateOrderResponse, error) {
-	order := &domain.Order{
-		CustomerID: req.CustomerID,
-		Amount:     req.Amount,
-		Status:     req.Status,
-	}
-
-	err := s.orderUseCase.CreateOrder(order)
+	order := toDomainOrder(req)
+	err := s.orderUseCase.CreateOrder(ctx, order, idempotencyKey(ctx))
 	if err != nil {
-		return nil, err
-	}
-
-	orderMsg := &OrderMessage{
-		ID:         uint32(order.ID),
-		CustomerID: order.CustomerID,
-		Amount:     order.Amount,
-		Status:     order.Status,
-		CreatedAt:  order.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:  order.UpdatedAt.Format(time.RFC3339),
+		return nil, statusError(err)
 	}
 
 	return &CreateOrderResponse{
-		Order: orderMsg,
+		Order: toOrderMessage(order),
 	}, nil
 }
 
 func (s *OrderService) ListOrders(ctx context.Context, req *ListOrdersRequest) (*ListOrdersResponse, error) {
-	orders, err := s.orderUseCase.ListOrders()
+	orders, err := s.orderUseCase.ListOrders(ctx, req.IncludeDeleted)
 	if err != nil {
-		return nil, err
+		return nil, statusError(err)
 	}
 
 	var orderMessages []*OrderMessage
-	for _, order := range orders {
-		orderMsg := &OrderMessage{
-			ID:         uint32(order.ID),
-			CustomerID: order.CustomerID,
-			Amount:     order.Amount,
-			Status:     order.Status,
-			CreatedAt:  order.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:  order.UpdatedAt.Format(time.RFC3339),
-		}
-		orderMessages = append(orderMessages, orderMsg)
+	for i := range orders {
+		orderMessages = append(orderMessages, toOrderMessage(&orders[i]))
 	}
 
 	return &ListOrdersResponse{
@@ -95,6 +165,70 @@ func (s *OrderService) ListOrders(ctx context.Context, req *ListOrdersRequest) (
 	}, nil
 }
 
+func (s *OrderService) UpdateOrderStatus(ctx context.Context, req *UpdateOrderStatusRequest) (*UpdateOrderStatusResponse, error) {
+	order, err := s.orderUseCase.UpdateOrderStatus(ctx, uint(req.ID), req.Status, uint(req.Version))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &UpdateOrderStatusResponse{
+		Order: toOrderMessage(order),
+	}, nil
+}
+
+func (s *OrderService) DeleteOrder(ctx context.Context, req *DeleteOrderRequest) (*DeleteOrderResponse, error) {
+	order, err := s.orderUseCase.DeleteOrder(ctx, uint(req.ID), uint(req.Version))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &DeleteOrderResponse{
+		Order: toOrderMessage(order),
+	}, nil
+}
+
+func (s *OrderService) RestoreOrder(ctx context.Context, req *RestoreOrderRequest) (*RestoreOrderResponse, error) {
+	order, err := s.orderUseCase.RestoreOrder(ctx, uint(req.ID), uint(req.Version))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &RestoreOrderResponse{
+		Order: toOrderMessage(order),
+	}, nil
+}
+
+// ImportOrders creates an order per received message. Messages that fail
+// validation are reported in the response; other failures end the call.
+func (s *OrderService) ImportOrders(stream ImportOrdersStream) error {
+	index := 0
+	report, err := s.orderUseCase.ImportOrders(stream.Context(), func() (usecase.ImportRow, error) {
+		req, err := stream.Recv()
+		if err != nil {
+			return usecase.ImportRow{}, err
+		}
+		index++
+		return usecase.ImportRow{Line: index, Order: toDomainOrder(req)}, nil
+	})
+	if err != nil {
+		return statusError(err)
+	}
+
+	resp := &ImportOrdersResponse{
+		ImportedCount: int32(report.Imported),
+		FailedCount:   int32(report.Failed),
+		Errors:        make([]*ImportOrderError, 0, len(report.Failures)),
+	}
+	for _, failure := range report.Failures {
+		resp.Errors = append(resp.Errors, &ImportOrderError{
+			Index:  int32(failure.Line),
+			Error:  failure.Error,
+			Fields: failure.Fields,
+		})
+	}
+	return stream.SendAndClose(resp)
+}
+
 // Helper method to convert to JSON for transport
 func (s *OrderService) ListOrdersJSON(ctx context.Context) ([]byte, error) {
 	req := &ListOrdersRequest{}
@@ -105,11 +239,11 @@ func (s *OrderService) ListOrdersJSON(ctx context.Context) ([]byte, error) {
 	return json.Marshal(resp)
 }
 
-func (s *OrderService) CreateOrderJSON(ctx context.Context, customerID string, amount float64, status string) ([]byte, error) {
+func (s *OrderService) CreateOrderJSON(ctx context.Context, customerID string, status string, items []*OrderItemMessage) ([]byte, error) {
 	req := &CreateOrderRequest{
 		CustomerID: customerID,
-		Amount:     amount,
 		Status:     status,
+		Items:      items,
 	}
 	resp, err := s.CreateOrder(ctx, req)
 	if err != nil {
@@ -117,3 +251,54 @@ func (s *OrderService) CreateOrderJSON(ctx context.Context, customerID string, a
 	}
 	return json.Marshal(resp)
 }
+
+// idempotencyKey reads the optional idempotency-key entry from the incoming metadata.
+func idempotencyKey(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(IdempotencyKeyMetadata); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func toDomainOrder(req *CreateOrderRequest) *domain.Order {
+	order := &domain.Order{
+		CustomerID: req.CustomerID,
+		Status:     req.Status,
+	}
+	for _, item := range req.Items {
+		order.Items = append(order.Items, domain.OrderItem{
+			ProductID:      item.ProductID,
+			Quantity:       item.Quantity,
+			UnitPriceCents: item.UnitPriceCents,
+		})
+	}
+	return order
+}
+
+func toOrderMessage(order *domain.Order) *OrderMessage {
+	orderMsg := &OrderMessage{
+		ID:          uint32(order.ID),
+		CustomerID:  order.CustomerID,
+		AmountCents: order.AmountCents,
+		Status:      order.Status,
+		Version:     uint32(order.Version),
+		CreatedAt:   order.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   order.UpdatedAt.Format(time.RFC3339),
+		Items:       make([]*OrderItemMessage, 0, len(order.Items)),
+	}
+	if order.DeletedAt.Valid {
+		orderMsg.DeletedAt = order.DeletedAt.Time.Format(time.RFC3339)
+	}
+	for _, item := range order.Items {
+		orderMsg.Items = append(orderMsg.Items, &OrderItemMessage{
+			ProductID:      item.ProductID,
+			Quantity:       item.Quantity,
+			UnitPriceCents: item.UnitPriceCents,
+		})
+	}
+	return orderMsg
+}
@@ -2,73 +2,212 @@ package grpc
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 	"trabalho-03/internal/usecase"
 
 	"github.com/gin-gonic/gin"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	grpcstatus "google.golang.org/grpc/status"
 )
 
 // GRPCServer simulates a gRPC server using HTTP/JSON
 type GRPCServer struct {
-	orderService *OrderService
+	orderService       *OrderService
+	interceptors       []grpclib.UnaryServerInterceptor
+	streamInterceptors []grpclib.StreamServerInterceptor
 }
 
-func NewGRPCServer(orderUseCase *usecase.OrderUseCase) *GRPCServer {
+// NewGRPCServer creates the server; every call goes through the unary
+// interceptors in the given order before reaching the OrderService.
+func NewGRPCServer(orderUseCase *usecase.OrderUseCase, interceptors ...grpclib.UnaryServerInterceptor) *GRPCServer {
 	return &GRPCServer{
 		orderService: NewOrderService(orderUseCase),
+		interceptors: interceptors,
 	}
 }
 
-func (s *GRPCServer) Start() {
+// WithStreamInterceptors sets the interceptors streaming calls go through, in
+// the given order, and returns s.
+func (s *GRPCServer) WithStreamInterceptors(interceptors ...grpclib.StreamServerInterceptor) *GRPCServer {
+	s.streamInterceptors = interceptors
+	return s
+}
+
+// invoke calls method through the interceptor chain.
+func (s *GRPCServer) invoke(ctx context.Context, method string, req any, handler grpclib.UnaryHandler) (any, error) {
+	info := &grpclib.UnaryServerInfo{Server: s.orderService, FullMethod: method}
+	return chainInterceptors(s.interceptors, info, handler)(ctx, req)
+}
+
+func (s *GRPCServer) createOrder(ctx context.Context, req *CreateOrderRequest) (any, error) {
+	return s.invoke(ctx, "/order.OrderService/CreateOrder", req, func(ctx context.Context, req any) (any, error) {
+		return s.orderService.CreateOrder(ctx, req.(*CreateOrderRequest))
+	})
+}
+
+func (s *GRPCServer) listOrders(ctx context.Context, req *ListOrdersRequest) (any, error) {
+	return s.invoke(ctx, "/order.OrderService/ListOrders", req, func(ctx context.Context, req any) (any, error) {
+		return s.orderService.ListOrders(ctx, req.(*ListOrdersRequest))
+	})
+}
+
+func (s *GRPCServer) updateOrderStatus(ctx context.Context, req *UpdateOrderStatusRequest) (any, error) {
+	return s.invoke(ctx, "/order.OrderService/UpdateOrderStatus", req, func(ctx context.Context, req any) (any, error) {
+		return s.orderService.UpdateOrderStatus(ctx, req.(*UpdateOrderStatusRequest))
+	})
+}
+
+func (s *GRPCServer) deleteOrder(ctx context.Context, req *DeleteOrderRequest) (any, error) {
+	return s.invoke(ctx, "/order.OrderService/DeleteOrder", req, func(ctx context.Context, req any) (any, error) {
+		return s.orderService.DeleteOrder(ctx, req.(*DeleteOrderRequest))
+	})
+}
+
+func (s *GRPCServer) restoreOrder(ctx context.Context, req *RestoreOrderRequest) (any, error) {
+	return s.invoke(ctx, "/order.OrderService/RestoreOrder", req, func(ctx context.Context, req any) (any, error) {
+		return s.orderService.RestoreOrder(ctx, req.(*RestoreOrderRequest))
+	})
+}
+
+func (s *GRPCServer) importOrders(stream *httpServerStream) error {
+	info := &grpclib.StreamServerInfo{FullMethod: "/order.OrderService/ImportOrders", IsClientStream: true}
+	handler := func(srv any, ss grpclib.ServerStream) error {
+		return srv.(*OrderService).ImportOrders(&importOrdersStream{ss})
+	}
+	return chainStreamInterceptors(s.streamInterceptors, info, handler)(s.orderService, stream)
+}
+
+// Handler returns the HTTP handler serving the gRPC-style endpoints.
+func (s *GRPCServer) Handler() http.Handler {
 	r := gin.New()
 	r.Use(gin.Logger())
-	
+
 	// gRPC-style endpoints
 	r.POST("/order.OrderService/CreateOrder", s.handleCreateOrder)
 	r.POST("/order.OrderService/ListOrders", s.handleListOrders)
-	
+	r.POST("/order.OrderService/UpdateOrderStatus", s.handleUpdateOrderStatus)
+	r.POST("/order.OrderService/DeleteOrder", s.handleDeleteOrder)
+	r.POST("/order.OrderService/RestoreOrder", s.handleRestoreOrder)
+	r.POST("/order.OrderService/ImportOrders", s.handleImportOrders)
+
 	// Alternative REST-like endpoints for easier testing
 	r.GET("/grpc/orders", s.handleListOrdersGET)
 	r.POST("/grpc/orders", s.handleCreateOrderREST)
-	
+
 	// Health check
 	r.GET("/grpc/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "healthy", "service": "OrderService"})
 	})
 
-	fmt.Println("gRPC-like server starting on port 9090")
-	if err := r.Run(":9090"); err != nil {
-		log.Fatal("Failed to start gRPC server:", err)
-	}
+	return r
 }
 
 func (s *GRPCServer) handleCreateOrder(c *gin.Context) {
 	var req CreateOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		writeStatus(c, invalidMessage(err))
 		return
 	}
 
-	ctx := context.Background()
-	resp, err := s.orderService.CreateOrder(ctx, &req)
+	ctx, cancel := incomingContext(c)
+	defer cancel()
+	resp, err := s.createOrder(ctx, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order", "details": err.Error()})
+		writeStatus(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, resp)
 }
 
+func (s *GRPCServer) handleUpdateOrderStatus(c *gin.Context) {
+	var req UpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeStatus(c, invalidMessage(err))
+		return
+	}
+
+	ctx, cancel := incomingContext(c)
+	defer cancel()
+	resp, err := s.updateOrderStatus(ctx, &req)
+	if err != nil {
+		writeStatus(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *GRPCServer) handleDeleteOrder(c *gin.Context) {
+	var req DeleteOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeStatus(c, invalidMessage(err))
+		return
+	}
+
+	ctx, cancel := incomingContext(c)
+	defer cancel()
+	resp, err := s.deleteOrder(ctx, &req)
+	if err != nil {
+		writeStatus(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *GRPCServer) handleRestoreOrder(c *gin.Context) {
+	var req RestoreOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeStatus(c, invalidMessage(err))
+		return
+	}
+
+	ctx, cancel := incomingContext(c)
+	defer cancel()
+	resp, err := s.restoreOrder(ctx, &req)
+	if err != nil {
+		writeStatus(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// handleImportOrders reads the client stream from the request body as a
+// sequence of JSON CreateOrderRequest messages.
+func (s *GRPCServer) handleImportOrders(c *gin.Context) {
+	ctx, cancel := incomingContext(c)
+	defer cancel()
+	stream := newHTTPServerStream(ctx, c.Request.Body)
+
+	if err := s.importOrders(stream); err != nil {
+		writeStatus(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stream.response)
+}
+
+// handleListOrders accepts an empty body as an empty ListOrdersRequest.
 func (s *GRPCServer) handleListOrders(c *gin.Context) {
-	ctx := context.Background()
 	req := &ListOrdersRequest{}
-	
-	resp, err := s.orderService.ListOrders(ctx, req)
+	if err := c.ShouldBindJSON(req); err != nil && !errors.Is(err, io.EOF) {
+		writeStatus(c, invalidMessage(err))
+		return
+	}
+
+	ctx, cancel := incomingContext(c)
+	defer cancel()
+	resp, err := s.listOrders(ctx, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list orders", "details": err.Error()})
+		writeStatus(c, err)
 		return
 	}
 
@@ -76,12 +215,13 @@ func (s *GRPCServer) handleListOrders(c *gin.Context) {
 }
 
 func (s *GRPCServer) handleListOrdersGET(c *gin.Context) {
-	ctx := context.Background()
+	ctx, cancel := incomingContext(c)
+	defer cancel()
 	req := &ListOrdersRequest{}
-	
-	resp, err := s.orderService.ListOrders(ctx, req)
+
+	resp, err := s.listOrders(ctx, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list orders", "details": err.Error()})
+		writeStatus(c, err)
 		return
 	}
 
@@ -90,27 +230,94 @@ func (s *GRPCServer) handleListOrdersGET(c *gin.Context) {
 
 func (s *GRPCServer) handleCreateOrderREST(c *gin.Context) {
 	customerID := c.PostForm("customer_id")
-	amountStr := c.PostForm("amount")
 	status := c.PostForm("status")
 
-	amount, err := strconv.ParseFloat(amountStr, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid amount format"})
+	// Items are sent as parallel repeated fields: product_id, quantity, unit_price_cents
+	productIDs := c.PostFormArray("product_id")
+	quantities := c.PostFormArray("quantity")
+	unitPrices := c.PostFormArray("unit_price_cents")
+	if len(quantities) != len(productIDs) || len(unitPrices) != len(productIDs) {
+		writeStatus(c, grpcstatus.Error(codes.InvalidArgument, "item fields product_id, quantity and unit_price_cents must have the same length"))
 		return
 	}
 
 	req := &CreateOrderRequest{
 		CustomerID: customerID,
-		Amount:     amount,
 		Status:     status,
 	}
+	for i, productID := range productIDs {
+		quantity, err := strconv.ParseInt(quantities[i], 10, 32)
+		if err != nil {
+			writeStatus(c, grpcstatus.Error(codes.InvalidArgument, "invalid quantity format"))
+			return
+		}
+		unitPrice, err := strconv.ParseInt(unitPrices[i], 10, 64)
+		if err != nil {
+			writeStatus(c, grpcstatus.Error(codes.InvalidArgument, "invalid unit_price_cents format"))
+			return
+		}
+		req.Items = append(req.Items, &OrderItemMessage{
+			ProductID:      productID,
+			Quantity:       int32(quantity),
+			UnitPriceCents: unitPrice,
+		})
+	}
 
-	ctx := context.Background()
-	resp, err := s.orderService.CreateOrder(ctx, req)
+	ctx, cancel := incomingContext(c)
+	defer cancel()
+	resp, err := s.createOrder(ctx, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order", "details": err.Error()})
+		writeStatus(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, resp)
 }
+
+// invalidMessage reports a request message that could not be decoded.
+func invalidMessage(err error) error {
+	return grpcstatus.Errorf(codes.InvalidArgument, "invalid message: %v", err)
+}
+
+// incomingContext exposes the HTTP headers as gRPC metadata and applies the
+// client's grpc-timeout header as the deadline, mirroring what a real gRPC
+// server hands to the service. The context is canceled when the client
+// disconnects.
+func incomingContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	md := metadata.MD{}
+	for name, values := range c.Request.Header {
+		md.Append(name, values...)
+	}
+	ctx := metadata.NewIncomingContext(c.Request.Context(), md)
+
+	if timeout, ok := parseTimeout(c.GetHeader("grpc-timeout")); ok {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+var timeoutUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+	'm': time.Millisecond,
+	'u': time.Microsecond,
+	'n': time.Nanosecond,
+}
+
+// parseTimeout decodes a grpc-timeout value: up to eight digits followed by a
+// unit (H, M, S, m, u or n), e.g. "500m" for 500 milliseconds.
+func parseTimeout(value string) (time.Duration, bool) {
+	if len(value) < 2 || len(value) > 9 {
+		return 0, false
+	}
+	unit, ok := timeoutUnits[value[len(value)-1]]
+	if !ok {
+		return 0, false
+	}
+	amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || amount < 0 {
+		return 0, false
+	}
+	return time.Duration(amount) * unit, true
+}
@@ -0,0 +1,213 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"trabalho-03/internal/auth"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/event"
+	"trabalho-03/internal/repository"
+	"trabalho-03/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// blockingRepository never answers List before ctx is done, like a slow query.
+type blockingRepository struct {
+	repository.OrderRepository
+}
+
+func (blockingRepository) List(ctx context.Context, includeDeleted bool) ([]domain.Order, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestParseTimeout(t *testing.T) {
+	tests := map[string]struct {
+		want time.Duration
+		ok   bool
+	}{
+		"500m":       {500 * time.Millisecond, true},
+		"2S":         {2 * time.Second, true},
+		"1H":         {time.Hour, true},
+		"":           {0, false},
+		"S":          {0, false},
+		"10x":        {0, false},
+		"123456789S": {0, false},
+	}
+	for value, tt := range tests {
+		got, ok := parseTimeout(value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseTimeout(%q) = %v, %v; want %v, %v", value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestGRPCServer_Deadlines(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authenticator := auth.NewAuthenticator("secret")
+	token, err := authenticator.Issue("", auth.RoleAdmin, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+	orderUseCase := usecase.NewOrderUseCase(blockingRepository{}, event.NewBus())
+
+	tests := map[string]struct {
+		serverTimeout time.Duration
+		grpcTimeout   string
+	}{
+		"server timeout":      {serverTimeout: 50 * time.Millisecond},
+		"client grpc-timeout": {serverTimeout: time.Minute, grpcTimeout: "50m"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := NewGRPCServer(orderUseCase, TimeoutInterceptor(tt.serverTimeout), AuthInterceptor(authenticator))
+
+			req := httptest.NewRequest(http.MethodPost, "/order.OrderService/ListOrders", strings.NewReader("{}"))
+			req.Header.Set("Authorization", "Bearer "+token)
+			if tt.grpcTimeout != "" {
+				req.Header.Set("grpc-timeout", tt.grpcTimeout)
+			}
+			rec := httptest.NewRecorder()
+
+			start := time.Now()
+			server.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusGatewayTimeout || !strings.Contains(rec.Body.String(), "DeadlineExceeded") {
+				t.Errorf("Expected 504 DeadlineExceeded, got %d %s", rec.Code, rec.Body.String())
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("Expected the call to stop at the deadline, took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestGRPCServer_ImportOrders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authenticator := auth.NewAuthenticator("secret")
+	token, err := authenticator.Issue("customer-1", auth.RoleCustomer, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+	repo := repository.NewMemoryOrderRepository()
+	server := NewGRPCServer(usecase.NewOrderUseCase(repo, event.NewBus())).
+		WithStreamInterceptors(TimeoutStreamInterceptor(time.Minute), AuthStreamInterceptor(authenticator))
+
+	stream := `{"customer_id": "customer-1", "status": "pending", "items": [{"product_id": "p", "quantity": 1, "unit_price_cents": 10}]}
+{"customer_id": "customer-1", "status": "lost", "items": [{"product_id": "p", "quantity": 1, "unit_price_cents": 10}]}
+{"customer_id": "customer-2", "status": "pending", "items": [{"product_id": "p", "quantity": 1, "unit_price_cents": 10}]}`
+
+	tests := map[string]struct {
+		body          string
+		authorization string
+		wantCode      int
+		wantBody      string
+	}{
+		"reports rejected messages": {
+			body:          stream,
+			authorization: "Bearer " + token,
+			wantCode:      http.StatusOK,
+			wantBody:      `{"imported_count":1,"failed_count":2,"errors":[{"index":2,"error":"validation failed","fields":[{"field":"status","message":"must be one of pending, confirmed, processing, shipped, delivered, cancelled"}]},{"index":3,"error":"` + auth.ErrForbidden.Error() + `"}]}`,
+		},
+		"requires authentication": {
+			body:     stream,
+			wantCode: http.StatusUnauthorized,
+		},
+		"rejects malformed messages": {
+			body:          `{"customer_id": "customer-1"} {"customer_id": `,
+			authorization: "Bearer " + token,
+			wantCode:      http.StatusBadRequest,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/order.OrderService/ImportOrders", strings.NewReader(tt.body))
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			server.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("Expected %d, got %d %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("Expected %s, got %s", tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestGRPCServer_ErrorStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authenticator := auth.NewAuthenticator("secret")
+	token, err := authenticator.Issue("", auth.RoleAdmin, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+	server := NewGRPCServer(usecase.NewOrderUseCase(repository.NewMemoryOrderRepository(), event.NewBus()),
+		AuthInterceptor(authenticator))
+
+	tests := map[string]struct {
+		method         string
+		body           string
+		authorization  string
+		wantHTTPStatus int
+		wantGRPCStatus string
+		wantBody       string
+	}{
+		"invalid argument": {
+			method:         "CreateOrder",
+			body:           `{"customer_id": "customer-1", "status": "pending", "items": []}`,
+			authorization:  "Bearer " + token,
+			wantHTTPStatus: http.StatusBadRequest,
+			wantGRPCStatus: "3",
+			wantBody:       `{"code":"InvalidArgument","message":"validation failed: items: must contain at least one item","details":[{"field":"items","message":"must contain at least one item"}]}`,
+		},
+		"not found": {
+			method:         "UpdateOrderStatus",
+			body:           `{"id": 42, "status": "shipped"}`,
+			authorization:  "Bearer " + token,
+			wantHTTPStatus: http.StatusNotFound,
+			wantGRPCStatus: "5",
+			wantBody:       `{"code":"NotFound","message":"order not found"}`,
+		},
+		"unauthenticated": {
+			method:         "ListOrders",
+			body:           `{}`,
+			wantHTTPStatus: http.StatusUnauthorized,
+			wantGRPCStatus: "16",
+			wantBody:       `{"code":"Unauthenticated","message":"authentication required"}`,
+		},
+		"malformed message": {
+			method:         "UpdateOrderStatus",
+			body:           `{"id": "one"}`,
+			authorization:  "Bearer " + token,
+			wantHTTPStatus: http.StatusBadRequest,
+			wantGRPCStatus: "3",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/order.OrderService/"+tt.method, strings.NewReader(tt.body))
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			server.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantHTTPStatus || rec.Header().Get("grpc-status") != tt.wantGRPCStatus {
+				t.Errorf("Expected %d with grpc-status %s, got %d with %q", tt.wantHTTPStatus, tt.wantGRPCStatus,
+					rec.Code, rec.Header().Get("grpc-status"))
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("Expected %s, got %s", tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// httpServerStream carries a client-streaming call over HTTP/JSON: the request
+// body holds one JSON message after another and the single response message is
+// kept for the handler to write once the call returns, so that a failing call
+// can still be answered with its status.
+type httpServerStream struct {
+	ctx      context.Context
+	decoder  *json.Decoder
+	response any
+}
+
+func newHTTPServerStream(ctx context.Context, body io.Reader) *httpServerStream {
+	return &httpServerStream{ctx: ctx, decoder: json.NewDecoder(body)}
+}
+
+func (s *httpServerStream) Context() context.Context { return s.ctx }
+
+func (s *httpServerStream) SetHeader(metadata.MD) error  { return nil }
+func (s *httpServerStream) SendHeader(metadata.MD) error { return nil }
+func (s *httpServerStream) SetTrailer(metadata.MD)       {}
+
+func (s *httpServerStream) SendMsg(m any) error {
+	if s.response != nil {
+		return status.Error(codes.Internal, "client-streaming calls send a single response")
+	}
+	s.response = m
+	return nil
+}
+
+// RecvMsg returns io.EOF once the client has sent every message. Reading the
+// body does not observe the context, so it is checked before each message.
+func (s *httpServerStream) RecvMsg(m any) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	err := s.decoder.Decode(m)
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid message: %v", err)
+	}
+	return nil
+}
+
+// contextStream overrides the context of a stream, as interceptors do to pass
+// values such as the caller's claims down to the service.
+type contextStream struct {
+	grpclib.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
+
+// chainStreamInterceptors runs the interceptors in order around handler, like
+// grpc.ChainStreamInterceptor does.
+func chainStreamInterceptors(interceptors []grpclib.StreamServerInterceptor, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) grpclib.StreamHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(srv any, ss grpclib.ServerStream) error {
+			return interceptor(srv, ss, info, next)
+		}
+	}
+	return handler
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"trabalho-03/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAuth rejects requests without a valid bearer token and stores the
+// caller's claims in the request context.
+func RequireAuth(authenticator *auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := authenticator.ParseAuthorization(c.GetHeader("Authorization"))
+		if err != nil {
+			c.Header("WWW-Authenticate", "Bearer")
+			writeError(c, err)
+			return
+		}
+		c.Request = c.Request.WithContext(auth.WithClaims(c.Request.Context(), claims))
+		c.Next()
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"trabalho-03/internal/bulk"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// importProblem is the problem returned when an import stops early.
+type importProblem struct {
+	Problem
+	Report *usecase.ImportReport `json:"report"`
+}
+
+// ImportOrders serves POST /orders/import. The body is decoded and imported
+// one record at a time while it is being received; the response lists the
+// records that were rejected. The format comes from ?format=csv|jsonl or,
+// when absent, from the Content-Type header.
+func (h *OrderHandler) ImportOrders(c *gin.Context) {
+	format := c.Query("format")
+	if format == "" {
+		format = formatOf(c.ContentType())
+	}
+	decoder, err := bulk.NewDecoder(format, c.Request.Body)
+	if err != nil {
+		writeError(c, domain.Errorf(domain.KindInvalid, "%w", err))
+		return
+	}
+
+	// readErr tells input that stopped being readable apart from failures
+	// to store the orders.
+	var readErr error
+	report, err := h.orderUseCase.ImportOrders(c.Request.Context(), func() (usecase.ImportRow, error) {
+		row, err := decoder.Next()
+		if err != nil && !errors.Is(err, io.EOF) {
+			readErr = err
+		}
+		return row, err
+	})
+	if readErr != nil && errors.Is(err, readErr) {
+		err = domain.Errorf(domain.KindInvalid, "failed to read input: %w", err)
+	}
+	if err != nil {
+		// The rows imported before the failure are kept, so the report
+		// tells the client where to resume.
+		problem := NewProblem(c, err)
+		writeProblem(c, problem, err, importProblem{Problem: problem, Report: report})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ExportOrders serves GET /orders/export?format=csv|jsonl (JSON Lines by
+// default), writing each batch of orders as soon as it is loaded. Once
+// streaming has started a failure can only be reported by cutting the
+// response short.
+func (h *OrderHandler) ExportOrders(c *gin.Context) {
+	format := c.DefaultQuery("format", bulk.FormatJSONL)
+	encoder, err := bulk.NewEncoder(format, c.Writer)
+	if err != nil {
+		writeError(c, domain.Errorf(domain.KindInvalid, "%w", err))
+		return
+	}
+
+	c.Header("Content-Type", bulk.ContentTypes[format])
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="orders.%s"`, format))
+	err = h.orderUseCase.ExportOrders(c.Request.Context(), func(orders []domain.Order) error {
+		if err := encoder.Encode(orders); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err == nil {
+		err = encoder.Flush()
+	}
+	if err == nil {
+		return
+	}
+	if !c.Writer.Written() {
+		c.Header("Content-Disposition", "")
+		writeError(c, err)
+		return
+	}
+	// Dropping the connection before the final chunk lets the client notice
+	// the export is incomplete instead of receiving a well-formed but
+	// truncated file.
+	log.Printf("Export of orders aborted: %v", err)
+	c.Abort()
+	if conn, _, err := c.Writer.Hijack(); err == nil {
+		conn.Close()
+	}
+}
+
+// formatOf returns the bulk format matching a Content-Type, or "".
+func formatOf(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	for format, formatType := range bulk.ContentTypes {
+		if mediaType == formatType {
+			return format
+		}
+	}
+	if mediaType == "application/jsonl" {
+		return bulk.FormatJSONL
+	}
+	return ""
+}
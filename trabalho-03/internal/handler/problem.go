@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/usecase/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// statusClientClosedRequest is the non-standard status logged when the client
+// disconnects before the response is written.
+const statusClientClosedRequest = 499
+
+var kindStatus = map[domain.Kind]int{
+	domain.KindInvalid:         http.StatusBadRequest,
+	domain.KindNotFound:        http.StatusNotFound,
+	domain.KindConflict:        http.StatusConflict,
+	domain.KindPrecondition:    http.StatusPreconditionFailed,
+	domain.KindUnauthenticated: http.StatusUnauthorized,
+	domain.KindForbidden:       http.StatusForbidden,
+	domain.KindTimeout:         http.StatusGatewayTimeout,
+	domain.KindCanceled:        statusClientClosedRequest,
+	domain.KindInternal:        http.StatusInternalServerError,
+}
+
+// Problem is an RFC 7807 problem details object. Code repeats the error kind,
+// which is also the code GraphQL clients see, and Errors lists the invalid
+// fields of BAD_USER_INPUT problems.
+type Problem struct {
+	Type     string                  `json:"type"`
+	Title    string                  `json:"title"`
+	Status   int                     `json:"status"`
+	Detail   string                  `json:"detail,omitempty"`
+	Instance string                  `json:"instance,omitempty"`
+	Code     domain.Kind             `json:"code"`
+	Errors   []validation.FieldError `json:"errors,omitempty"`
+}
+
+// NewProblem describes err for the request being handled by c.
+func NewProblem(c *gin.Context, err error) Problem {
+	kind, detail := domain.Describe(err)
+	status := kindStatus[kind]
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		Code:     kind,
+	}
+	var validationErrs validation.Errors
+	if errors.As(err, &validationErrs) {
+		problem.Detail = "validation failed"
+		problem.Errors = validationErrs
+	}
+	return problem
+}
+
+// writeError answers the request with the problem details of err. Requests
+// whose client went away get no body, and internal errors are logged since
+// their message is not sent.
+func writeError(c *gin.Context, err error) {
+	problem := NewProblem(c, err)
+	writeProblem(c, problem, err, problem)
+}
+
+// writeProblem is writeError for bodies that extend problem with more members.
+func writeProblem(c *gin.Context, problem Problem, err error, body any) {
+	switch problem.Code {
+	case domain.KindCanceled:
+		c.AbortWithStatus(problem.Status)
+		return
+	case domain.KindInternal:
+		log.Printf("%s %s failed: %v", c.Request.Method, c.Request.URL.Path, err)
+	}
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(problem.Status, body)
+}
+
+// NotFound answers requests that match no route.
+func NotFound(c *gin.Context) {
+	writeError(c, domain.Errorf(domain.KindNotFound, "no route for %s %s", c.Request.Method, c.Request.URL.Path))
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"trabalho-03/internal/auth"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/repository"
+	"trabalho-03/internal/usecase/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWriteError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := map[string]struct {
+		err        error
+		wantStatus int
+		wantCode   domain.Kind
+		wantDetail string
+	}{
+		"validation": {
+			err:        validation.Errors{{Field: "status", Message: "must not be empty"}},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.KindInvalid,
+			wantDetail: "validation failed",
+		},
+		"not found":       {repository.ErrOrderNotFound, http.StatusNotFound, domain.KindNotFound, "order not found"},
+		"conflict":        {repository.ErrIdempotencyKeyConflict, http.StatusConflict, domain.KindConflict, repository.ErrIdempotencyKeyConflict.Error()},
+		"forbidden":       {auth.ErrForbidden, http.StatusForbidden, domain.KindForbidden, auth.ErrForbidden.Error()},
+		"unauthenticated": {auth.ErrUnauthenticated, http.StatusUnauthorized, domain.KindUnauthenticated, auth.ErrUnauthenticated.Error()},
+		"internal":        {errors.New("connection refused"), http.StatusInternalServerError, domain.KindInternal, "internal server error"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/order", nil)
+
+			writeError(c, tt.err)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != ProblemContentType {
+				t.Errorf("Expected Content-Type %s, got %s", ProblemContentType, got)
+			}
+			var problem Problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("Failed to decode %s: %v", rec.Body.String(), err)
+			}
+			if problem.Status != tt.wantStatus || problem.Code != tt.wantCode || problem.Detail != tt.wantDetail ||
+				problem.Title != http.StatusText(tt.wantStatus) || problem.Instance != "/order" {
+				t.Errorf("Unexpected problem %+v", problem)
+			}
+			if tt.wantCode == domain.KindInvalid && len(problem.Errors) != 1 {
+				t.Errorf("Expected the invalid fields to be listed, got %+v", problem.Errors)
+			}
+		})
+	}
+}
@@ -2,8 +2,11 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 	"trabalho-03/internal/domain"
 	"trabalho-03/internal/usecase"
+	"trabalho-03/internal/usecase/validation"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,28 +19,226 @@ func NewOrderHandler(orderUseCase *usecase.OrderUseCase) *OrderHandler {
 	return &OrderHandler{orderUseCase: orderUseCase}
 }
 
+// createOrderRequest lists the fields a client may set; ID, amount and
+// timestamps are always assigned by the server.
+type createOrderRequest struct {
+	CustomerID string                   `json:"customer_id"`
+	Status     string                   `json:"status"`
+	Items      []createOrderItemRequest `json:"items"`
+}
+
+type createOrderItemRequest struct {
+	ProductID      string `json:"product_id"`
+	Quantity       int32  `json:"quantity"`
+	UnitPriceCents int64  `json:"unit_price_cents"`
+}
+
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
-	var order domain.Order
-	if err := c.ShouldBindJSON(&order); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req createOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, invalidBody(err))
 		return
 	}
 
-	err := h.orderUseCase.CreateOrder(&order)
+	order := domain.Order{
+		CustomerID: req.CustomerID,
+		Status:     req.Status,
+	}
+	for _, item := range req.Items {
+		order.Items = append(order.Items, domain.OrderItem{
+			ProductID:      item.ProductID,
+			Quantity:       item.Quantity,
+			UnitPriceCents: item.UnitPriceCents,
+		})
+	}
+
+	err := h.orderUseCase.CreateOrder(c.Request.Context(), &order, c.GetHeader("Idempotency-Key"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 
+	setETag(c, &order)
 	c.JSON(http.StatusCreated, order)
 }
 
+// ListOrders serves GET /order; admins may add include_deleted=true to list
+// soft-deleted orders as well.
 func (h *OrderHandler) ListOrders(c *gin.Context) {
-	orders, err := h.orderUseCase.ListOrders()
+	includeDeleted := false
+	if value := c.Query("include_deleted"); value != "" {
+		var err error
+		if includeDeleted, err = strconv.ParseBool(value); err != nil {
+			writeError(c, validation.Errors{{Field: "include_deleted", Message: "must be true or false"}})
+			return
+		}
+	}
+
+	orders, err := h.orderUseCase.ListOrders(c.Request.Context(), includeDeleted)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, orders)
 }
+
+// GetOrder serves GET /order/:id. Its ETag is the current version, to be sent
+// back in If-Match by the next update.
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	id, err := orderID(c)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	order, err := h.orderUseCase.GetOrder(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	setETag(c, order)
+	c.JSON(http.StatusOK, order)
+}
+
+type updateOrderStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// UpdateOrderStatus honours If-Match: when it carries the ETag of an earlier
+// response and the order has changed since, the update fails with 412.
+func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
+	id, version, err := orderTarget(c)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	var req updateOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, invalidBody(err))
+		return
+	}
+
+	order, err := h.orderUseCase.UpdateOrderStatus(c.Request.Context(), id, req.Status, version)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	setETag(c, order)
+	c.JSON(http.StatusOK, order)
+}
+
+// DeleteOrder soft-deletes an order; it honours If-Match like UpdateOrderStatus.
+func (h *OrderHandler) DeleteOrder(c *gin.Context) {
+	id, version, err := orderTarget(c)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	if _, err := h.orderUseCase.DeleteOrder(c.Request.Context(), id, version); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreOrder serves POST /order/:id/restore for admins.
+func (h *OrderHandler) RestoreOrder(c *gin.Context) {
+	id, version, err := orderTarget(c)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	order, err := h.orderUseCase.RestoreOrder(c.Request.Context(), id, version)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	setETag(c, order)
+	c.JSON(http.StatusOK, order)
+}
+
+// Report serves GET /orders/report?group_by=customer|status|day&from=&to=.
+// from and to are parsed by domain.ParseReportTime.
+func (h *OrderHandler) Report(c *gin.Context) {
+	filter := domain.ReportFilter{GroupBy: c.Query("group_by")}
+	var fieldErrs validation.Errors
+	var err error
+	if filter.From, err = domain.ParseReportTime(c.Query("from"), false); err != nil {
+		fieldErrs = append(fieldErrs, validation.FieldError{Field: "from", Message: err.Error()})
+	}
+	if filter.To, err = domain.ParseReportTime(c.Query("to"), true); err != nil {
+		fieldErrs = append(fieldErrs, validation.FieldError{Field: "to", Message: err.Error()})
+	}
+	if len(fieldErrs) > 0 {
+		writeError(c, fieldErrs)
+		return
+	}
+
+	stats, err := h.orderUseCase.OrderStats(c.Request.Context(), filter)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"group_by": filter.GroupBy, "groups": stats})
+}
+
+// orderTarget reads the order id from the path and the expected version from
+// If-Match.
+func orderTarget(c *gin.Context) (id uint, version uint, err error) {
+	id, err = orderID(c)
+	if err != nil {
+		return 0, 0, err
+	}
+	version, err = ifMatchVersion(c.GetHeader("If-Match"))
+	if err != nil {
+		return 0, 0, err
+	}
+	return id, version, nil
+}
+
+// orderID reads the order id from the path.
+func orderID(c *gin.Context) (uint, error) {
+	parsed, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, domain.Errorf(domain.KindInvalid, "invalid order id %q", c.Param("id"))
+	}
+	return uint(parsed), nil
+}
+
+// setETag exposes the order version as a strong entity tag.
+func setETag(c *gin.Context, order *domain.Order) {
+	c.Header("ETag", `"`+strconv.FormatUint(uint64(order.Version), 10)+`"`)
+}
+
+// ifMatchVersion returns the order version named by an If-Match header, or
+// zero when the header is absent or "*". Only a single tag as sent in ETag is
+// understood; weak tags never match under If-Match and are rejected.
+func ifMatchVersion(header string) (uint, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	tag, ok := strings.CutPrefix(header, `"`)
+	if ok {
+		tag, ok = strings.CutSuffix(tag, `"`)
+	}
+	version, err := strconv.ParseUint(tag, 10, 64)
+	if !ok || err != nil || version == 0 {
+		return 0, domain.Errorf(domain.KindInvalid, "If-Match must be an ETag returned by the API, got %s", header)
+	}
+	return uint(version), nil
+}
+
+// invalidBody reports a request body that could not be decoded.
+func invalidBody(err error) error {
+	return domain.Errorf(domain.KindInvalid, "invalid request body: %w", err)
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"trabalho-03/internal/auth"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/event"
+	"trabalho-03/internal/repository"
+	"trabalho-03/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := map[string]struct {
+		header      string
+		wantVersion uint
+		wantErr     bool
+	}{
+		"absent":      {header: "", wantVersion: 0},
+		"any":         {header: "*", wantVersion: 0},
+		"strong tag":  {header: `"3"`, wantVersion: 3},
+		"padded":      {header: ` "12" `, wantVersion: 12},
+		"weak tag":    {header: `W/"3"`, wantErr: true},
+		"unquoted":    {header: "3", wantErr: true},
+		"list":        {header: `"3", "4"`, wantErr: true},
+		"zero":        {header: `"0"`, wantErr: true},
+		"not numeric": {header: `"abc"`, wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			version, err := ifMatchVersion(tt.header)
+			if tt.wantErr {
+				var domainErr *domain.Error
+				if !errors.As(err, &domainErr) || domainErr.Kind != domain.KindInvalid {
+					t.Errorf("Expected a BAD_USER_INPUT error, got %v", err)
+				}
+				return
+			}
+			if err != nil || version != tt.wantVersion {
+				t.Errorf("Expected version %d, got %d (err %v)", tt.wantVersion, version, err)
+			}
+		})
+	}
+}
+
+func TestOrderHandler_GetOrder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authenticator := auth.NewAuthenticator("secret")
+	orderUseCase := usecase.NewOrderUseCase(repository.NewMemoryOrderRepository(), event.NewBus())
+	router := NewRouter(NewOrderHandler(orderUseCase), authenticator, http.NotFoundHandler(), time.Second, time.Second)
+
+	// serve sends the request as customerID, or as an admin when it is empty.
+	serve := func(method, target, customerID, ifMatch, body string) *httptest.ResponseRecorder {
+		role := auth.RoleCustomer
+		if customerID == "" {
+			role = auth.RoleAdmin
+		}
+		token, err := authenticator.Issue(customerID, role, time.Hour)
+		if err != nil {
+			t.Fatalf("Failed to issue token: %v", err)
+		}
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	created := serve(http.MethodPost, "/order", "customer-1", "",
+		`{"customer_id": "customer-1", "status": "pending", "items": [{"product_id": "p", "quantity": 1, "unit_price_cents": 500}]}`)
+	if created.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", created.Code, created.Body)
+	}
+	serve(http.MethodPatch, "/order/1/status", "", "", `{"status": "confirmed"}`)
+
+	rec := serve(http.MethodGet, "/order/1", "customer-1", "", "")
+	var order domain.Order
+	if err := json.Unmarshal(rec.Body.Bytes(), &order); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Expected the order, got %d: %s", rec.Code, rec.Body)
+	}
+	if order.Status != domain.OrderStatusConfirmed || len(order.Items) != 1 || rec.Header().Get("ETag") != `"2"` {
+		t.Errorf("Expected the current order with ETag \"2\", got %+v with ETag %s", order, rec.Header().Get("ETag"))
+	}
+
+	// The ETag is the If-Match of the next update.
+	if rec := serve(http.MethodPatch, "/order/1/status", "customer-1", `"2"`, `{"status": "shipped"}`); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the update to succeed, got %d", rec.Code)
+	}
+	if rec := serve(http.MethodPatch, "/order/1/status", "", `"2"`, `{"status": "delivered"}`); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected 412 for the stale ETag, got %d", rec.Code)
+	}
+
+	for target, want := range map[string]int{"/order/2": http.StatusNotFound, "/order/abc": http.StatusBadRequest} {
+		if rec := serve(http.MethodGet, target, "", "", ""); rec.Code != want {
+			t.Errorf("GET %s: expected %d, got %d", target, want, rec.Code)
+		}
+	}
+	if rec := serve(http.MethodGet, "/order/1", "customer-2", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected another customer's order to be hidden, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+	"trabalho-03/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewRouter serves the REST API. Bulk import and export get bulkTimeout
+// instead of timeout; readiness is served unauthenticated at /readyz.
+func NewRouter(orderHandler *OrderHandler, authenticator *auth.Authenticator, readiness http.Handler, timeout, bulkTimeout time.Duration) http.Handler {
+	r := gin.Default()
+	r.Use(Telemetry())
+	r.NoRoute(NotFound)
+
+	orders := r.Group("/order", Timeout(timeout), RequireAuth(authenticator))
+	orders.POST("", orderHandler.CreateOrder)
+	orders.GET("", orderHandler.ListOrders)
+	orders.GET("/:id", orderHandler.GetOrder)
+	orders.PATCH("/:id/status", orderHandler.UpdateOrderStatus)
+	orders.DELETE("/:id", orderHandler.DeleteOrder)
+	orders.POST("/:id/restore", orderHandler.RestoreOrder)
+	reports := r.Group("/orders", Timeout(timeout), RequireAuth(authenticator))
+	reports.GET("/report", orderHandler.Report)
+	bulk := r.Group("/orders", Timeout(bulkTimeout), RequireAuth(authenticator))
+	bulk.POST("/import", orderHandler.ImportOrders)
+	bulk.GET("/export", orderHandler.ExportOrders)
+	r.GET("/readyz", gin.WrapH(readiness))
+
+	return r
+}
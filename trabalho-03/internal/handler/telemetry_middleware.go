@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"trabalho-03/internal/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Telemetry traces every request and records its RED metrics under the
+// matched route, e.g. "POST /order".
+func Telemetry() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx, request := telemetry.StartRequest(c.Request.Context(), telemetry.TransportREST,
+			c.Request.Method+" "+route, propagation.HeaderCarrier(c.Request.Header))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		var err error
+		if status >= http.StatusInternalServerError {
+			err = fmt.Errorf("responded with status %d", status)
+		}
+		request.End(strconv.Itoa(status), err)
+	}
+}
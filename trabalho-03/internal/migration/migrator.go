@@ -0,0 +1,256 @@
+package migration
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+var createTablePattern = regexp.MustCompile(`(?i)CREATE\s+TABLE\s+IF\s+NOT\s+EXISTS\s+(\w+)`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// appliedMigration is a row of schema_migrations.
+type appliedMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (appliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator applies the migrations found under the directory named after the
+// database dialect (e.g. "postgres" or "sqlite"). Each migration runs in its
+// own transaction together with its schema_migrations bookkeeping.
+//
+// A database that already has tables the migrations create, but no recorded
+// migration, predates them (its tables came from GORM AutoMigrate). Up
+// refuses to adopt such tables as they are; with a legacy converter set, it
+// runs the converter first, in the first migration's transaction.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	legacy     func(tx *gorm.DB) error
+}
+
+func NewMigrator(db *gorm.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := load(fsys, db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&appliedMigration{}); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// WithLegacyConverter sets convert to bring a database that predates the
+// migrations to the schema the first migration expects. Tables it leaves in
+// place are then adopted by the migrations' CREATE TABLE IF NOT EXISTS.
+func (m *Migrator) WithLegacyConverter(convert func(tx *gorm.DB) error) *Migrator {
+	m.legacy = convert
+	return m
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations for %s: %w", dir, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies every pending migration in order and returns the ones applied.
+func (m *Migrator) Up() ([]Migration, error) {
+	pending, err := m.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	legacy, err := m.legacyTables()
+	if err != nil {
+		return nil, err
+	}
+	if len(legacy) > 0 && m.legacy == nil {
+		return nil, fmt.Errorf("database has tables %v but no recorded migration; refusing to adopt them without a legacy converter", legacy)
+	}
+
+	var applied []Migration
+	for i, migration := range pending {
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if i == 0 && len(legacy) > 0 {
+				if err := m.legacy(tx); err != nil {
+					return fmt.Errorf("failed to convert legacy tables %v: %w", legacy, err)
+				}
+			}
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			return tx.Create(&appliedMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		applied = append(applied, migration)
+	}
+	return applied, nil
+}
+
+// legacyTables lists the tables the migrations create that already exist
+// while no migration is recorded.
+func (m *Migrator) legacyTables() ([]string, error) {
+	var recorded int64
+	if err := m.db.Model(&appliedMigration{}).Count(&recorded).Error; err != nil {
+		return nil, err
+	}
+	if recorded > 0 {
+		return nil, nil
+	}
+
+	var tables []string
+	seen := make(map[string]bool)
+	for _, migration := range m.migrations {
+		for _, match := range createTablePattern.FindAllStringSubmatch(migration.Up, -1) {
+			table := match[1]
+			if !seen[table] && m.db.Migrator().HasTable(table) {
+				tables = append(tables, table)
+			}
+			seen[table] = true
+		}
+	}
+	return tables, nil
+}
+
+// Down reverts the most recently applied migration. It returns nil when there
+// is nothing to revert.
+func (m *Migrator) Down() (*Migration, error) {
+	var last appliedMigration
+	result := m.db.Order("version DESC").Limit(1).Find(&last)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	var migration *Migration
+	for i := range m.migrations {
+		if m.migrations[i].Version == last.Version {
+			migration = &m.migrations[i]
+		}
+	}
+	if migration == nil {
+		return nil, fmt.Errorf("applied migration %d_%s is unknown to this binary", last.Version, last.Name)
+	}
+	if migration.Down == "" {
+		return nil, fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+	}
+
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migration.Down).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&appliedMigration{}, migration.Version).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reverting migration %d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+	return migration, nil
+}
+
+func (m *Migrator) Status() ([]Status, error) {
+	var rows []appliedMigration
+	if err := m.db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row.AppliedAt
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) Pending() ([]Migration, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status.Migration)
+		}
+	}
+	return pending, nil
+}
@@ -0,0 +1,164 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+	"trabalho-03/migrations"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open SQLite: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	migrator, err := NewMigrator(db, migrations.FS)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	pending, err := migrator.Pending()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(pending) == 0 {
+		t.Fatal("Expected pending migrations on an empty database")
+	}
+
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(applied) != len(pending) {
+		t.Errorf("Expected %d migrations applied, got %d", len(pending), len(applied))
+	}
+	if !db.Migrator().HasTable("orders") {
+		t.Error("Expected orders table to exist")
+	}
+
+	// Revert everything, then apply again to check every down file.
+	for range applied {
+		reverted, err := migrator.Down()
+		if err != nil || reverted == nil {
+			t.Fatalf("Expected a migration to be reverted, got %v, %v", reverted, err)
+		}
+	}
+	if db.Migrator().HasTable("orders") {
+		t.Error("Expected orders table to be dropped")
+	}
+	if reverted, err := migrator.Down(); err != nil || reverted != nil {
+		t.Errorf("Expected nothing to revert, got %v, %v", reverted, err)
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Expected migrations to apply again, got %v", err)
+	}
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, status := range statuses {
+		if !status.Applied {
+			t.Errorf("Expected %04d_%s to be applied", status.Version, status.Name)
+		}
+	}
+}
+
+func TestMigrator_RefusesLegacyTables(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open SQLite: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	// The orders table as GORM AutoMigrate created it before migrations.
+	if err := db.Exec(`CREATE TABLE orders (id INTEGER PRIMARY KEY AUTOINCREMENT, customer_id TEXT, amount REAL, status TEXT,
+		created_at DATETIME, updated_at DATETIME, deleted_at DATETIME)`).Error; err != nil {
+		t.Fatalf("Failed to create legacy table: %v", err)
+	}
+
+	migrator, err := NewMigrator(db, migrations.FS)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err == nil || !strings.Contains(err.Error(), "orders") {
+		t.Fatalf("Expected the legacy orders table to be refused, got %v", err)
+	}
+	if pending, _ := migrator.Pending(); len(pending) != len(migrator.migrations) {
+		t.Errorf("Expected no migration to be recorded, got %d pending", len(pending))
+	}
+
+	var converted bool
+	migrator.WithLegacyConverter(func(tx *gorm.DB) error {
+		converted = true
+		return tx.Exec("DROP TABLE orders").Error
+	})
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Expected migrations to apply after conversion, got %v", err)
+	}
+	if !converted || !db.Migrator().HasColumn("orders", "amount_cents") {
+		t.Error("Expected the converter to run before the first migration")
+	}
+}
+
+func TestMigrator_ConvertsLegacyOrders(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open SQLite: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	// AutoMigrate added amount_cents next to the float amount once amounts
+	// became cents: older orders only have amount, newer ones only cents.
+	for _, statement := range []string{
+		`CREATE TABLE orders (id INTEGER PRIMARY KEY AUTOINCREMENT, customer_id TEXT, amount REAL, status TEXT,
+			created_at DATETIME, updated_at DATETIME, deleted_at DATETIME, amount_cents INTEGER)`,
+		`INSERT INTO orders (id, customer_id, amount, status) VALUES (1, 'c1', 19.99, 'pending'), (2, 'c2', 0.1, 'shipped')`,
+		`INSERT INTO orders (id, customer_id, amount_cents, status) VALUES (3, 'c3', 4500, 'pending')`,
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("Failed to create legacy data: %v", err)
+		}
+	}
+
+	migrator, err := NewMigrator(db, migrations.FS)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := migrator.WithLegacyConverter(migrations.ConvertLegacy).Up(); err != nil {
+		t.Fatalf("Expected the legacy database to be converted, got %v", err)
+	}
+
+	if db.Migrator().HasColumn("orders", "amount") {
+		t.Error("Expected orders.amount to be dropped")
+	}
+	var rows []struct {
+		ID          uint
+		AmountCents int64
+		Version     uint
+	}
+	if err := db.Raw("SELECT id, amount_cents, version FROM orders ORDER BY id").Scan(&rows).Error; err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []int64{1999, 10, 4500}
+	if len(rows) != len(want) {
+		t.Fatalf("Expected %d orders, got %d", len(want), len(rows))
+	}
+	for i, row := range rows {
+		if row.AmountCents != want[i] || row.Version != 1 {
+			t.Errorf("Order %d: expected %d cents at version 1, got %d at %d", row.ID, want[i], row.AmountCents, row.Version)
+		}
+	}
+
+	// New orders keep getting ids after the legacy ones.
+	if err := db.Exec("INSERT INTO orders (customer_id, amount_cents, status) VALUES ('c4', 100, 'pending')").Error; err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
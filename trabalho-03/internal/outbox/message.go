@@ -0,0 +1,37 @@
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Message is a domain event waiting to be published. It is written in the same
+// transaction as the change that produced it.
+type Message struct {
+	ID          uint   `gorm:"primaryKey"`
+	EventType   string `gorm:"not null"`
+	AggregateID uint   `gorm:"index;not null"`
+	Payload     []byte `gorm:"not null"`
+	Attempts    int    `gorm:"not null;default:0"`
+	LastError   string
+	CreatedAt   time.Time
+	PublishedAt *time.Time `gorm:"index"`
+}
+
+func (Message) TableName() string {
+	return "outbox_messages"
+}
+
+func NewMessage(eventType string, aggregateID uint, event any) (*Message, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+
+	return &Message{
+		EventType:   eventType,
+		AggregateID: aggregateID,
+		Payload:     payload,
+	}, nil
+}
@@ -0,0 +1,56 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+	"trabalho-03/internal/broker"
+)
+
+// Relay polls the outbox and publishes pending messages in insertion order.
+// A message is only marked as published after the broker accepts it, so a crash
+// between both steps results in a redelivery (at-least-once).
+type Relay struct {
+	store     Store
+	broker    broker.Broker
+	interval  time.Duration
+	batchSize int
+}
+
+func NewRelay(store Store, broker broker.Broker, interval time.Duration, batchSize int) *Relay {
+	return &Relay{
+		store:     store,
+		broker:    broker,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.PublishPending(ctx); err != nil {
+			log.Println("Outbox relay:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishPending publishes one batch and returns how many messages were delivered.
+func (r *Relay) PublishPending(ctx context.Context) (int, error) {
+	return r.store.ProcessPending(ctx, r.batchSize, func(msg Message) error {
+		return r.broker.Publish(ctx, broker.Message{
+			ID:      strconv.FormatUint(uint64(msg.ID), 10),
+			Topic:   msg.EventType,
+			Payload: msg.Payload,
+		})
+	})
+}
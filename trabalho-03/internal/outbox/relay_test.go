@@ -0,0 +1,87 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"trabalho-03/internal/broker"
+	"trabalho-03/internal/migration"
+	"trabalho-03/migrations"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+type failingBroker struct {
+	broker.Broker
+	failures int
+}
+
+func (b *failingBroker) Publish(ctx context.Context, msg broker.Message) error {
+	if b.failures > 0 {
+		b.failures--
+		return errors.New("broker unavailable")
+	}
+	return b.Broker.Publish(ctx, msg)
+}
+
+func TestRelay_PublishPendingRetriesAfterFailure(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open SQLite: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	migrator, err := migration.NewMigrator(db, migrations.FS)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	for i := uint(1); i <= 3; i++ {
+		msg, err := NewMessage("order.created", i, map[string]uint{"order_id": i})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := db.Create(msg).Error; err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	memory := broker.NewMemoryBroker()
+	relay := NewRelay(NewGormStore(db), &failingBroker{Broker: memory, failures: 1}, time.Second, 10)
+
+	published, err := relay.PublishPending(context.Background())
+	if err == nil || published != 0 {
+		t.Fatalf("Expected first batch to fail without publishing, got published=%d err=%v", published, err)
+	}
+
+	var failed Message
+	db.First(&failed)
+	if failed.Attempts != 1 || failed.LastError == "" {
+		t.Errorf("Expected failure to be recorded, got attempts=%d last_error=%q", failed.Attempts, failed.LastError)
+	}
+
+	published, err = relay.PublishPending(context.Background())
+	if err != nil || published != 3 {
+		t.Fatalf("Expected 3 messages published, got published=%d err=%v", published, err)
+	}
+
+	messages := memory.Messages()
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 messages in the broker, got %d", len(messages))
+	}
+	for i, msg := range messages {
+		if want := []string{"1", "2", "3"}[i]; msg.ID != want {
+			t.Errorf("Expected message %d to have ID %s, got %s", i, want, msg.ID)
+		}
+	}
+
+	published, _ = relay.PublishPending(context.Background())
+	if published != 0 {
+		t.Errorf("Expected nothing left to publish, got %d", published)
+	}
+}
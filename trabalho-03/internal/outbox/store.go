@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Store gives the relay access to pending messages. ProcessPending calls publish
+// for up to limit unpublished messages in insertion order, marks each one as
+// published after publish succeeds and stops at the first failure, recording it
+// on the message. It returns how many messages were published.
+type Store interface {
+	ProcessPending(ctx context.Context, limit int, publish func(Message) error) (int, error)
+}
+
+type GormStore struct {
+	db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+func (s *GormStore) ProcessPending(ctx context.Context, limit int, publish func(Message) error) (int, error) {
+	published := 0
+	var publishErr error
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("published_at IS NULL").Order("id").Limit(limit)
+		if tx.Dialector.Name() == "postgres" {
+			// Lets several instances relay concurrently without double publishing a batch.
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+
+		var messages []Message
+		if err := query.Find(&messages).Error; err != nil {
+			return err
+		}
+
+		for _, msg := range messages {
+			if err := publish(msg); err != nil {
+				publishErr = err
+				return tx.Model(&Message{}).Where("id = ?", msg.ID).Updates(map[string]any{
+					"attempts":   gorm.Expr("attempts + 1"),
+					"last_error": err.Error(),
+				}).Error
+			}
+
+			if err := tx.Model(&Message{}).Where("id = ?", msg.ID).Update("published_at", time.Now()).Error; err != nil {
+				return err
+			}
+			published++
+		}
+		return nil
+	})
+	if err != nil {
+		return published, err
+	}
+	return published, publishErr
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"trabalho-03/internal/domain"
+)
+
+// CustomerRepository stores customer profiles.
+type CustomerRepository interface {
+	// Upsert creates the customer or replaces the name and email of an existing one.
+	Upsert(ctx context.Context, customer *domain.Customer) error
+	// FindByIDs returns the customers that exist among ids, in no particular order.
+	FindByIDs(ctx context.Context, ids []string) ([]domain.Customer, error)
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"trabalho-03/internal/domain"
+)
+
+func testCustomerRepositories(t *testing.T) map[string]CustomerRepository {
+	return map[string]CustomerRepository{
+		"memory": NewMemoryCustomerRepository(),
+		"sqlite": NewGormCustomerRepository(testDB(t)),
+	}
+}
+
+func TestCustomerRepository_UpsertAndFindByIDs(t *testing.T) {
+	for name, repo := range testCustomerRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			first := &domain.Customer{ID: "customer-1", Name: "Ana", Email: "ana@example.com"}
+			if err := repo.Upsert(ctx, first); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := repo.Upsert(ctx, &domain.Customer{ID: "customer-2", Name: "Bruno", Email: "bruno@example.com"}); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			updated := &domain.Customer{ID: "customer-1", Name: "Ana Maria", Email: "ana.maria@example.com"}
+			if err := repo.Upsert(ctx, updated); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !updated.CreatedAt.Equal(first.CreatedAt) {
+				t.Errorf("Expected CreatedAt to be kept on update, got %v and %v", first.CreatedAt, updated.CreatedAt)
+			}
+
+			customers, err := repo.FindByIDs(ctx, []string{"customer-1", "customer-3"})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(customers) != 1 {
+				t.Fatalf("Expected 1 customer, got %d", len(customers))
+			}
+			if customers[0].Name != "Ana Maria" || customers[0].Email != "ana.maria@example.com" {
+				t.Errorf("Expected updated customer, got %+v", customers[0])
+			}
+		})
+	}
+}
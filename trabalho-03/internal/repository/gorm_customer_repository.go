@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"trabalho-03/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormCustomerRepository struct {
+	db *gorm.DB
+}
+
+func NewGormCustomerRepository(db *gorm.DB) *GormCustomerRepository {
+	return &GormCustomerRepository{db: db}
+}
+
+// Upsert reloads the row afterwards so CreatedAt reflects the original insert.
+func (r *GormCustomerRepository) Upsert(ctx context.Context, customer *domain.Customer) error {
+	db := r.db.WithContext(ctx)
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "email", "updated_at"}),
+	}).Create(customer).Error
+	if err != nil {
+		return err
+	}
+	return db.Take(customer, "id = ?", customer.ID).Error
+}
+
+func (r *GormCustomerRepository) FindByIDs(ctx context.Context, ids []string) ([]domain.Customer, error) {
+	var customers []domain.Customer
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&customers).Error
+	return customers, err
+}
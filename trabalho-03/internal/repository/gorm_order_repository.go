@@ -0,0 +1,345 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/outbox"
+
+	"gorm.io/gorm"
+)
+
+// GormOrderRepository stores orders in a relational database (Postgres or SQLite).
+type GormOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewGormOrderRepository(db *gorm.DB) *GormOrderRepository {
+	return &GormOrderRepository{db: db}
+}
+
+// Create stores the order and its OrderCreated event atomically.
+func (r *GormOrderRepository) Create(ctx context.Context, order *domain.Order) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createOrder(tx, order)
+	})
+}
+
+// CreateIdempotent behaves like Create but records the result under key. When
+// the key was already used for the same request, order is filled from the
+// stored snapshot and replayed is true. Concurrent requests with the same key
+// are resolved by the primary key on idempotency_keys: the loser replays.
+func (r *GormOrderRepository) CreateIdempotent(ctx context.Context, order *domain.Order, key, requestHash string) (replayed bool, err error) {
+	db := r.db.WithContext(ctx)
+	replayed, err = replay(db, order, key, requestHash)
+	if replayed || err != nil {
+		return replayed, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := createOrder(tx, order); err != nil {
+			return err
+		}
+
+		snapshot, err := json.Marshal(order)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&IdempotencyRecord{
+			Key:         key,
+			RequestHash: requestHash,
+			OrderID:     order.ID,
+			Response:    snapshot,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		*order = domain.Order{}
+		return replay(db, order, key, requestHash)
+	}
+	return false, err
+}
+
+func replay(db *gorm.DB, order *domain.Order, key, requestHash string) (bool, error) {
+	var record IdempotencyRecord
+	err := db.Where(&IdempotencyRecord{Key: key}).Take(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if record.RequestHash != requestHash {
+		return false, ErrIdempotencyKeyConflict
+	}
+	if err := json.Unmarshal(record.Response, order); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func createOrder(tx *gorm.DB, order *domain.Order) error {
+	order.Version = 1
+	if err := tx.Create(order).Error; err != nil {
+		return err
+	}
+
+	return createMessage(tx, domain.EventOrderCreated, order.ID, domain.OrderCreated{
+		OrderID:     order.ID,
+		CustomerID:  order.CustomerID,
+		AmountCents: order.AmountCents,
+		Status:      order.Status,
+		OccurredAt:  order.CreatedAt,
+	})
+}
+
+// createMessage adds an event to the outbox within tx.
+func createMessage(tx *gorm.DB, eventType string, orderID uint, evt any) error {
+	msg, err := outbox.NewMessage(eventType, orderID, evt)
+	if err != nil {
+		return err
+	}
+	return tx.Create(msg).Error
+}
+
+func (r *GormOrderRepository) List(ctx context.Context, includeDeleted bool) ([]domain.Order, error) {
+	query := r.db.WithContext(ctx)
+	if includeDeleted {
+		query = query.Unscoped()
+	}
+	var orders []domain.Order
+	err := query.Preload("Items").Find(&orders).Error
+	return orders, err
+}
+
+func (r *GormOrderRepository) ListByCustomer(ctx context.Context, customerID string) ([]domain.Order, error) {
+	var orders []domain.Order
+	err := r.db.WithContext(ctx).Preload("Items").Where("customer_id = ?", customerID).Find(&orders).Error
+	return orders, err
+}
+
+// ListInBatches pages through the orders by primary key, so each batch is a
+// separate query and no cursor is held while fn runs.
+func (r *GormOrderRepository) ListInBatches(ctx context.Context, customerID string, batchSize int, fn func([]domain.Order) error) error {
+	query := r.db.WithContext(ctx).Preload("Items")
+	if customerID != "" {
+		query = query.Where("customer_id = ?", customerID)
+	}
+
+	var batch []domain.Order
+	return query.FindInBatches(&batch, batchSize, func(*gorm.DB, int) error {
+		return fn(batch)
+	}).Error
+}
+
+func (r *GormOrderRepository) FindByID(ctx context.Context, id uint) (*domain.Order, error) {
+	order := &domain.Order{}
+	err := r.db.WithContext(ctx).Preload("Items").First(order, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// UpdateStatus changes the order status and records an OrderStatusChanged event
+// in the same transaction. Setting the current status again is a no-op and
+// reports changed as false. The UPDATE is conditioned on the version that was
+// read, so a concurrent change between the read and the write is reported as
+// ErrVersionConflict instead of being overwritten.
+func (r *GormOrderRepository) UpdateStatus(ctx context.Context, id uint, status string, version uint) (order *domain.Order, changed bool, err error) {
+	order = &domain.Order{}
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Items").First(order, id).Error; err != nil {
+			return err
+		}
+		if version != 0 && order.Version != version {
+			return ErrVersionConflict
+		}
+		if order.Status == status {
+			return nil
+		}
+
+		oldStatus := order.Status
+		if err := updateVersioned(tx, order, map[string]any{"status": status}); err != nil {
+			return err
+		}
+		order.Status = status
+
+		if err := createMessage(tx, domain.EventOrderStatusChanged, order.ID, domain.OrderStatusChanged{
+			OrderID:    order.ID,
+			CustomerID: order.CustomerID,
+			OldStatus:  oldStatus,
+			NewStatus:  status,
+			OccurredAt: time.Now(),
+		}); err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return order, changed, nil
+}
+
+// Delete sets deleted_at, which hides the order from every scoped query, and
+// records an OrderDeleted event in the same transaction.
+func (r *GormOrderRepository) Delete(ctx context.Context, id uint, version uint) (*domain.Order, error) {
+	order := &domain.Order{}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Items").First(order, id).Error; err != nil {
+			return err
+		}
+		if version != 0 && order.Version != version {
+			return ErrVersionConflict
+		}
+
+		now := time.Now()
+		if err := updateVersioned(tx, order, map[string]any{"deleted_at": now}); err != nil {
+			return err
+		}
+		order.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+
+		return createMessage(tx, domain.EventOrderDeleted, order.ID, domain.OrderDeleted{
+			OrderID:    order.ID,
+			CustomerID: order.CustomerID,
+			OccurredAt: now,
+		})
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// Restore clears deleted_at and records an OrderRestored event. Orders that
+// were already purged are reported as ErrOrderNotFound.
+func (r *GormOrderRepository) Restore(ctx context.Context, id uint, version uint) (order *domain.Order, changed bool, err error) {
+	order = &domain.Order{}
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Preload("Items").First(order, id).Error; err != nil {
+			return err
+		}
+		if version != 0 && order.Version != version {
+			return ErrVersionConflict
+		}
+		if !order.DeletedAt.Valid {
+			return nil
+		}
+
+		if err := updateVersioned(tx.Unscoped(), order, map[string]any{"deleted_at": nil}); err != nil {
+			return err
+		}
+		order.DeletedAt = gorm.DeletedAt{}
+
+		if err := createMessage(tx, domain.EventOrderRestored, order.ID, domain.OrderRestored{
+			OrderID:    order.ID,
+			CustomerID: order.CustomerID,
+			OccurredAt: time.Now(),
+		}); err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return order, changed, nil
+}
+
+// Purge hard-deletes in a single transaction. Items go with their order
+// through the ON DELETE CASCADE foreign key; idempotency keys are removed
+// explicitly so a retried request cannot replay a purged order.
+func (r *GormOrderRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&domain.Order{}).Select("id").Where("deleted_at < ?", deletedBefore)
+		if err := tx.Where("order_id IN (?)", expired).Delete(&IdempotencyRecord{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Where("deleted_at < ?", deletedBefore).Delete(&domain.Order{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+// updateVersioned applies updates only if the stored version is still the one
+// in order, incrementing it; otherwise another request changed the order in
+// between and ErrVersionConflict is returned.
+func updateVersioned(tx *gorm.DB, order *domain.Order, updates map[string]any) error {
+	updates["version"] = gorm.Expr("version + 1")
+	result := tx.Model(order).Where("version = ?", order.Version).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	order.Version++
+	return nil
+}
+
+// Stats runs a single GROUP BY query. Day keys are computed by the database in
+// UTC so both dialects report the same days.
+func (r *GormOrderRepository) Stats(ctx context.Context, filter domain.ReportFilter) ([]domain.OrderStats, error) {
+	key, err := r.groupKey(filter.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	query := r.db.WithContext(ctx).Model(&domain.Order{})
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	if filter.CustomerID != "" {
+		query = query.Where("customer_id = ?", filter.CustomerID)
+	}
+
+	stats := []domain.OrderStats{}
+	err = query.
+		Select(key + " AS key, COUNT(*) AS count, COALESCE(SUM(amount_cents), 0) AS total_amount_cents, AVG(amount_cents) AS average_amount_cents").
+		Group(key).
+		Order("key").
+		Scan(&stats).Error
+	return stats, err
+}
+
+func (r *GormOrderRepository) groupKey(groupBy string) (string, error) {
+	switch groupBy {
+	case domain.ReportGroupByCustomer:
+		return "customer_id", nil
+	case domain.ReportGroupByStatus:
+		return "status", nil
+	case domain.ReportGroupByDay:
+		if r.db.Dialector.Name() == "postgres" {
+			return "to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')", nil
+		}
+		return "strftime('%Y-%m-%d', created_at)", nil
+	}
+	return "", fmt.Errorf("unsupported report grouping %q", groupBy)
+}
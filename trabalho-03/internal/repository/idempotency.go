@@ -0,0 +1,18 @@
+package repository
+
+import "time"
+
+// IdempotencyRecord remembers the order created for an Idempotency-Key together
+// with a snapshot of the response, so retried requests can be answered without
+// inserting again.
+type IdempotencyRecord struct {
+	Key         string `gorm:"primaryKey;size:255"`
+	RequestHash string `gorm:"size:64;not null"`
+	OrderID     uint   `gorm:"not null"`
+	Response    []byte `gorm:"not null"`
+	CreatedAt   time.Time
+}
+
+func (IdempotencyRecord) TableName() string {
+	return "idempotency_keys"
+}
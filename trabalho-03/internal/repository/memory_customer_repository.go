@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+	"trabalho-03/internal/domain"
+)
+
+type MemoryCustomerRepository struct {
+	mutex     sync.RWMutex
+	customers map[string]domain.Customer
+}
+
+func NewMemoryCustomerRepository() *MemoryCustomerRepository {
+	return &MemoryCustomerRepository{customers: make(map[string]domain.Customer)}
+}
+
+func (r *MemoryCustomerRepository) Upsert(ctx context.Context, customer *domain.Customer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	customer.UpdatedAt = now
+	if existing, exists := r.customers[customer.ID]; exists {
+		customer.CreatedAt = existing.CreatedAt
+	} else {
+		customer.CreatedAt = now
+	}
+	r.customers[customer.ID] = *customer
+	return nil
+}
+
+func (r *MemoryCustomerRepository) FindByIDs(ctx context.Context, ids []string) ([]domain.Customer, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	customers := make([]domain.Customer, 0, len(ids))
+	for _, id := range ids {
+		if customer, exists := r.customers[id]; exists {
+			customers = append(customers, customer)
+		}
+	}
+	return customers, nil
+}
@@ -0,0 +1,408 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/outbox"
+
+	"gorm.io/gorm"
+)
+
+// MemoryOrderRepository keeps everything in process memory. It implements
+// outbox.Store as well so the relay works without a database; outbox only holds
+// the messages not yet published.
+type MemoryOrderRepository struct {
+	mutex        sync.RWMutex
+	relayMutex   sync.Mutex
+	orders       map[uint]*domain.Order
+	idempotency  map[string]IdempotencyRecord
+	outbox       []outbox.Message
+	nextOrderID  uint
+	nextItemID   uint
+	nextOutboxID uint
+}
+
+func NewMemoryOrderRepository() *MemoryOrderRepository {
+	return &MemoryOrderRepository{
+		orders:      make(map[uint]*domain.Order),
+		idempotency: make(map[string]IdempotencyRecord),
+	}
+}
+
+func (r *MemoryOrderRepository) Create(ctx context.Context, order *domain.Order) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.create(order)
+}
+
+func (r *MemoryOrderRepository) CreateIdempotent(ctx context.Context, order *domain.Order, key, requestHash string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if record, exists := r.idempotency[key]; exists {
+		if record.RequestHash != requestHash {
+			return false, ErrIdempotencyKeyConflict
+		}
+		return true, json.Unmarshal(record.Response, order)
+	}
+
+	if err := r.create(order); err != nil {
+		return false, err
+	}
+
+	snapshot, err := json.Marshal(order)
+	if err != nil {
+		return false, err
+	}
+	r.idempotency[key] = IdempotencyRecord{
+		Key:         key,
+		RequestHash: requestHash,
+		OrderID:     order.ID,
+		Response:    snapshot,
+		CreatedAt:   time.Now(),
+	}
+	return false, nil
+}
+
+func (r *MemoryOrderRepository) List(ctx context.Context, includeDeleted bool) ([]domain.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.list(includeDeleted, func(*domain.Order) bool { return true }), nil
+}
+
+func (r *MemoryOrderRepository) ListByCustomer(ctx context.Context, customerID string) ([]domain.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.list(false, func(order *domain.Order) bool { return order.CustomerID == customerID }), nil
+}
+
+// ListInBatches works on a snapshot taken up front, so fn runs without the lock.
+func (r *MemoryOrderRepository) ListInBatches(ctx context.Context, customerID string, batchSize int, fn func([]domain.Order) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	orders := r.list(false, func(order *domain.Order) bool { return customerID == "" || order.CustomerID == customerID })
+
+	for start := 0; start < len(orders); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := min(start+batchSize, len(orders))
+		if err := fn(orders[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MemoryOrderRepository) FindByID(ctx context.Context, id uint) (*domain.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	stored, exists := r.live(id)
+	if !exists {
+		return nil, ErrOrderNotFound
+	}
+	order := copyOrder(stored)
+	return &order, nil
+}
+
+// live returns the stored order unless it does not exist or is soft-deleted.
+func (r *MemoryOrderRepository) live(id uint) (*domain.Order, bool) {
+	order, exists := r.orders[id]
+	if !exists || order.DeletedAt.Valid {
+		return nil, false
+	}
+	return order, true
+}
+
+// list returns the orders accepted by match in ID order.
+func (r *MemoryOrderRepository) list(includeDeleted bool, match func(*domain.Order) bool) []domain.Order {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	orders := make([]domain.Order, 0, len(r.orders))
+	for id := uint(1); id <= r.nextOrderID; id++ {
+		order, exists := r.orders[id]
+		if !exists || order.DeletedAt.Valid && !includeDeleted {
+			continue
+		}
+		if match(order) {
+			orders = append(orders, copyOrder(order))
+		}
+	}
+	return orders
+}
+
+func (r *MemoryOrderRepository) UpdateStatus(ctx context.Context, id uint, status string, version uint) (*domain.Order, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, exists := r.live(id)
+	if !exists {
+		return nil, false, ErrOrderNotFound
+	}
+	if version != 0 && stored.Version != version {
+		return nil, false, ErrVersionConflict
+	}
+	if stored.Status == status {
+		order := copyOrder(stored)
+		return &order, false, nil
+	}
+
+	now := time.Now()
+	if err := r.appendOutbox(domain.EventOrderStatusChanged, id, domain.OrderStatusChanged{
+		OrderID:    id,
+		CustomerID: stored.CustomerID,
+		OldStatus:  stored.Status,
+		NewStatus:  status,
+		OccurredAt: now,
+	}); err != nil {
+		return nil, false, err
+	}
+	stored.Status = status
+	stored.Version++
+	stored.UpdatedAt = now
+
+	order := copyOrder(stored)
+	return &order, true, nil
+}
+
+func (r *MemoryOrderRepository) Delete(ctx context.Context, id uint, version uint) (*domain.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, exists := r.live(id)
+	if !exists {
+		return nil, ErrOrderNotFound
+	}
+	if version != 0 && stored.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	now := time.Now()
+	if err := r.appendOutbox(domain.EventOrderDeleted, id, domain.OrderDeleted{
+		OrderID:    id,
+		CustomerID: stored.CustomerID,
+		OccurredAt: now,
+	}); err != nil {
+		return nil, err
+	}
+	stored.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	stored.Version++
+	stored.UpdatedAt = now
+
+	order := copyOrder(stored)
+	return &order, nil
+}
+
+func (r *MemoryOrderRepository) Restore(ctx context.Context, id uint, version uint) (*domain.Order, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, exists := r.orders[id]
+	if !exists {
+		return nil, false, ErrOrderNotFound
+	}
+	if version != 0 && stored.Version != version {
+		return nil, false, ErrVersionConflict
+	}
+	if !stored.DeletedAt.Valid {
+		order := copyOrder(stored)
+		return &order, false, nil
+	}
+
+	now := time.Now()
+	if err := r.appendOutbox(domain.EventOrderRestored, id, domain.OrderRestored{
+		OrderID:    id,
+		CustomerID: stored.CustomerID,
+		OccurredAt: now,
+	}); err != nil {
+		return nil, false, err
+	}
+	stored.DeletedAt = gorm.DeletedAt{}
+	stored.Version++
+	stored.UpdatedAt = now
+
+	order := copyOrder(stored)
+	return &order, true, nil
+}
+
+func (r *MemoryOrderRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var purged int64
+	for id, order := range r.orders {
+		if order.DeletedAt.Valid && order.DeletedAt.Time.Before(deletedBefore) {
+			delete(r.orders, id)
+			purged++
+		}
+	}
+	for key, record := range r.idempotency {
+		if _, exists := r.orders[record.OrderID]; !exists {
+			delete(r.idempotency, key)
+		}
+	}
+	return purged, nil
+}
+
+func (r *MemoryOrderRepository) Stats(ctx context.Context, filter domain.ReportFilter) ([]domain.OrderStats, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var key func(*domain.Order) string
+	switch filter.GroupBy {
+	case domain.ReportGroupByCustomer:
+		key = func(order *domain.Order) string { return order.CustomerID }
+	case domain.ReportGroupByStatus:
+		key = func(order *domain.Order) string { return order.Status }
+	case domain.ReportGroupByDay:
+		key = func(order *domain.Order) string { return order.CreatedAt.UTC().Format(domain.ReportDayLayout) }
+	default:
+		return nil, fmt.Errorf("unsupported report grouping %q", filter.GroupBy)
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	groups := make(map[string]*domain.OrderStats)
+	for _, order := range r.orders {
+		if order.DeletedAt.Valid ||
+			!filter.From.IsZero() && order.CreatedAt.Before(filter.From) ||
+			!filter.To.IsZero() && !order.CreatedAt.Before(filter.To) ||
+			filter.CustomerID != "" && order.CustomerID != filter.CustomerID {
+			continue
+		}
+		k := key(order)
+		group, exists := groups[k]
+		if !exists {
+			group = &domain.OrderStats{Key: k}
+			groups[k] = group
+		}
+		group.Count++
+		group.TotalAmountCents += order.AmountCents
+	}
+
+	stats := make([]domain.OrderStats, 0, len(groups))
+	for _, group := range groups {
+		group.AverageAmountCents = float64(group.TotalAmountCents) / float64(group.Count)
+		stats = append(stats, *group)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Key < stats[j].Key })
+	return stats, nil
+}
+
+// ProcessPending publishes without holding the repository lock, so a slow
+// broker does not stall the other methods; relays are serialized by their own
+// lock instead. Published messages are dropped, so the outbox only holds the
+// pending ones, oldest first.
+func (r *MemoryOrderRepository) ProcessPending(ctx context.Context, limit int, publish func(outbox.Message) error) (int, error) {
+	r.relayMutex.Lock()
+	defer r.relayMutex.Unlock()
+
+	r.mutex.RLock()
+	pending := r.outbox
+	if limit >= 0 && limit < len(pending) {
+		pending = pending[:limit]
+	}
+	pending = append([]outbox.Message(nil), pending...)
+	r.mutex.RUnlock()
+
+	published := 0
+	var publishErr error
+	for _, msg := range pending {
+		if publishErr = publish(msg); publishErr != nil {
+			break
+		}
+		published++
+	}
+
+	// Only ProcessPending removes messages and new ones are appended, so the
+	// published ones are still at the head of the outbox.
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.outbox = append([]outbox.Message(nil), r.outbox[published:]...)
+	if publishErr != nil {
+		r.outbox[0].Attempts++
+		r.outbox[0].LastError = publishErr.Error()
+	}
+	return published, publishErr
+}
+
+// create must be called with the write lock held.
+func (r *MemoryOrderRepository) create(order *domain.Order) error {
+	now := time.Now()
+	r.nextOrderID++
+	order.ID = r.nextOrderID
+	order.Version = 1
+	order.CreatedAt = now
+	order.UpdatedAt = now
+	for i := range order.Items {
+		r.nextItemID++
+		order.Items[i].ID = r.nextItemID
+		order.Items[i].OrderID = order.ID
+	}
+
+	if err := r.appendOutbox(domain.EventOrderCreated, order.ID, domain.OrderCreated{
+		OrderID:     order.ID,
+		CustomerID:  order.CustomerID,
+		AmountCents: order.AmountCents,
+		Status:      order.Status,
+		OccurredAt:  now,
+	}); err != nil {
+		return err
+	}
+
+	stored := copyOrder(order)
+	r.orders[order.ID] = &stored
+	return nil
+}
+
+func (r *MemoryOrderRepository) appendOutbox(eventType string, aggregateID uint, evt any) error {
+	msg, err := outbox.NewMessage(eventType, aggregateID, evt)
+	if err != nil {
+		return err
+	}
+	r.nextOutboxID++
+	msg.ID = r.nextOutboxID
+	msg.CreatedAt = time.Now()
+	r.outbox = append(r.outbox, *msg)
+	return nil
+}
+
+func copyOrder(order *domain.Order) domain.Order {
+	c := *order
+	c.Items = append([]domain.OrderItem(nil), order.Items...)
+	return c
+}
@@ -1,24 +1,44 @@
 package repository
 
 import (
+	"context"
+	"time"
 	"trabalho-03/internal/domain"
-	"gorm.io/gorm"
 )
 
-type OrderRepository struct {
-	db *gorm.DB
-}
-
-func NewOrderRepository(db *gorm.DB) *OrderRepository {
-	return &OrderRepository{db: db}
-}
-
-func (r *OrderRepository) Create(order *domain.Order) error {
-	return r.db.Create(order).Error
-}
+var (
+	ErrOrderNotFound          = &domain.Error{Kind: domain.KindNotFound, Message: "order not found"}
+	ErrIdempotencyKeyConflict = &domain.Error{Kind: domain.KindConflict, Message: "idempotency key was already used with a different request"}
+	ErrVersionConflict        = &domain.Error{Kind: domain.KindPrecondition, Message: "order was modified by another request"}
+)
 
-func (r *OrderRepository) List() ([]domain.Order, error) {
-	var orders []domain.Order
-	err := r.db.Find(&orders).Error
-	return orders, err
+// OrderRepository persists orders together with the outbox events they produce.
+// Every method stops and returns the context error once ctx is done.
+// Soft-deleted orders are invisible to every method except List with
+// includeDeleted, Restore and Purge.
+type OrderRepository interface {
+	Create(ctx context.Context, order *domain.Order) error
+	// CreateIdempotent creates the order once per key; see GormOrderRepository.CreateIdempotent.
+	CreateIdempotent(ctx context.Context, order *domain.Order, key, requestHash string) (replayed bool, err error)
+	List(ctx context.Context, includeDeleted bool) ([]domain.Order, error)
+	ListByCustomer(ctx context.Context, customerID string) ([]domain.Order, error)
+	FindByID(ctx context.Context, id uint) (*domain.Order, error)
+	// ListInBatches hands the orders of customerID (every order when empty) to
+	// fn in ID order, at most batchSize at a time, stopping at the first error.
+	ListInBatches(ctx context.Context, customerID string, batchSize int, fn func([]domain.Order) error) error
+	// UpdateStatus reports changed as false when the order already had status.
+	// A non-zero version must match the stored one or ErrVersionConflict is
+	// returned; a change increments the version.
+	UpdateStatus(ctx context.Context, id uint, status string, version uint) (order *domain.Order, changed bool, err error)
+	// Delete soft-deletes the order and increments its version. A non-zero
+	// version must match the stored one, as in UpdateStatus.
+	Delete(ctx context.Context, id uint, version uint) (*domain.Order, error)
+	// Restore undoes Delete. Restoring an order that is not deleted is a no-op
+	// and reports changed as false.
+	Restore(ctx context.Context, id uint, version uint) (order *domain.Order, changed bool, err error)
+	// Purge permanently removes the orders soft-deleted before deletedBefore,
+	// with their items and idempotency keys, and returns how many were removed.
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+	// Stats aggregates the orders matching filter per group, ordered by key.
+	Stats(ctx context.Context, filter domain.ReportFilter) ([]domain.OrderStats, error)
 }
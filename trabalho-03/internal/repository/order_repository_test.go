@@ -0,0 +1,496 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/migration"
+	"trabalho-03/internal/outbox"
+	"trabalho-03/migrations"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// testDB returns a migrated in-memory SQLite database.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:?_pragma=foreign_keys(1)"), &gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatalf("Failed to open SQLite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Failed to get SQLite pool: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := migration.NewMigrator(db, migrations.FS)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Failed to migrate SQLite: %v", err)
+	}
+	return db
+}
+
+// testRepositories returns every OrderRepository implementation that can run
+// without external services, each backed by fresh storage.
+func testRepositories(t *testing.T) map[string]OrderRepository {
+	return map[string]OrderRepository{
+		"memory": NewMemoryOrderRepository(),
+		"sqlite": NewGormOrderRepository(testDB(t)),
+	}
+}
+
+func newTestOrder(customerID string) *domain.Order {
+	order := &domain.Order{
+		CustomerID: customerID,
+		Status:     domain.OrderStatusPending,
+		Items: []domain.OrderItem{
+			{ProductID: "product-1", Quantity: 2, UnitPriceCents: 1050},
+			{ProductID: "product-2", Quantity: 1, UnitPriceCents: 399},
+		},
+	}
+	order.CalculateAmount()
+	return order
+}
+
+func TestOrderRepository_CreateAndList(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			order := newTestOrder("customer-1")
+			if err := repo.Create(ctx, order); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if order.ID == 0 {
+				t.Error("Expected order ID to be assigned")
+			}
+
+			orders, err := repo.List(ctx, false)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(orders) != 1 {
+				t.Fatalf("Expected 1 order, got %d", len(orders))
+			}
+			if orders[0].AmountCents != 2499 {
+				t.Errorf("Expected amount 2499, got %d", orders[0].AmountCents)
+			}
+			if len(orders[0].Items) != 2 {
+				t.Errorf("Expected 2 items, got %d", len(orders[0].Items))
+			}
+		})
+	}
+}
+
+func TestOrderRepository_CreateIdempotent(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			first := newTestOrder("customer-1")
+			replayed, err := repo.CreateIdempotent(ctx, first, "key-1", "hash-1")
+			if err != nil || replayed {
+				t.Fatalf("Expected first create to insert, got replayed=%v err=%v", replayed, err)
+			}
+
+			retry := newTestOrder("customer-1")
+			replayed, err = repo.CreateIdempotent(ctx, retry, "key-1", "hash-1")
+			if err != nil || !replayed {
+				t.Fatalf("Expected retry to replay, got replayed=%v err=%v", replayed, err)
+			}
+			if retry.ID != first.ID {
+				t.Errorf("Expected replayed order ID %d, got %d", first.ID, retry.ID)
+			}
+
+			_, err = repo.CreateIdempotent(ctx, newTestOrder("customer-2"), "key-1", "hash-2")
+			if !errors.Is(err, ErrIdempotencyKeyConflict) {
+				t.Errorf("Expected ErrIdempotencyKeyConflict, got %v", err)
+			}
+
+			orders, _ := repo.List(ctx, false)
+			if len(orders) != 1 {
+				t.Errorf("Expected 1 order, got %d", len(orders))
+			}
+		})
+	}
+}
+
+func TestOrderRepository_UpdateStatus(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			order := newTestOrder("customer-1")
+			if err := repo.Create(ctx, order); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			updated, changed, err := repo.UpdateStatus(ctx, order.ID, domain.OrderStatusConfirmed, 0)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !changed || updated.Status != domain.OrderStatusConfirmed {
+				t.Errorf("Expected status to change to confirmed, got changed=%v status=%s", changed, updated.Status)
+			}
+
+			_, changed, err = repo.UpdateStatus(ctx, order.ID, domain.OrderStatusConfirmed, 0)
+			if err != nil || changed {
+				t.Errorf("Expected same status to be a no-op, got changed=%v err=%v", changed, err)
+			}
+
+			_, _, err = repo.UpdateStatus(ctx, order.ID+100, domain.OrderStatusConfirmed, 0)
+			if !errors.Is(err, ErrOrderNotFound) {
+				t.Errorf("Expected ErrOrderNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_UpdateStatusChecksVersion(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			order := newTestOrder("customer-1")
+			if err := repo.Create(ctx, order); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if order.Version != 1 {
+				t.Fatalf("Expected new order at version 1, got %d", order.Version)
+			}
+
+			updated, _, err := repo.UpdateStatus(ctx, order.ID, domain.OrderStatusConfirmed, 1)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if updated.Version != 2 {
+				t.Errorf("Expected version 2 after the update, got %d", updated.Version)
+			}
+
+			_, _, err = repo.UpdateStatus(ctx, order.ID, domain.OrderStatusCancelled, 1)
+			if !errors.Is(err, ErrVersionConflict) {
+				t.Errorf("Expected ErrVersionConflict for a stale version, got %v", err)
+			}
+
+			stored, err := repo.FindByID(ctx, order.ID)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if stored.Status != domain.OrderStatusConfirmed || stored.Version != 2 {
+				t.Errorf("Expected confirmed at version 2, got %s at version %d", stored.Status, stored.Version)
+			}
+
+			_, changed, err := repo.UpdateStatus(ctx, order.ID, domain.OrderStatusConfirmed, 2)
+			if err != nil || changed {
+				t.Errorf("Expected same status to be a no-op, got changed=%v err=%v", changed, err)
+			}
+			if stored, _ := repo.FindByID(ctx, order.ID); stored.Version != 2 {
+				t.Errorf("Expected a no-op to keep version 2, got %d", stored.Version)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_DeleteRestoreAndPurge(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			deleted := newTestOrder("customer-1")
+			if _, err := repo.CreateIdempotent(ctx, deleted, "key-1", "hash-1"); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			kept := newTestOrder("customer-1")
+			if err := repo.Create(ctx, kept); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if _, err := repo.Delete(ctx, deleted.ID, 2); !errors.Is(err, ErrVersionConflict) {
+				t.Errorf("Expected ErrVersionConflict for a stale version, got %v", err)
+			}
+			order, err := repo.Delete(ctx, deleted.ID, 1)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !order.DeletedAt.Valid || order.Version != 2 {
+				t.Errorf("Expected a deleted order at version 2, got %+v", order)
+			}
+
+			if _, err := repo.FindByID(ctx, deleted.ID); !errors.Is(err, ErrOrderNotFound) {
+				t.Errorf("Expected deleted order to be hidden, got %v", err)
+			}
+			if orders, _ := repo.ListByCustomer(ctx, "customer-1"); len(orders) != 1 {
+				t.Errorf("Expected 1 live order, got %d", len(orders))
+			}
+			if orders, _ := repo.List(ctx, true); len(orders) != 2 {
+				t.Errorf("Expected 2 orders including deleted, got %d", len(orders))
+			}
+			if _, _, err := repo.UpdateStatus(ctx, deleted.ID, domain.OrderStatusConfirmed, 0); !errors.Is(err, ErrOrderNotFound) {
+				t.Errorf("Expected deleted order not to be updatable, got %v", err)
+			}
+
+			order, changed, err := repo.Restore(ctx, deleted.ID, 0)
+			if err != nil || !changed || order.DeletedAt.Valid || order.Version != 3 {
+				t.Fatalf("Expected order restored at version 3, got %+v changed=%v err=%v", order, changed, err)
+			}
+			if _, changed, err := repo.Restore(ctx, deleted.ID, 0); err != nil || changed {
+				t.Errorf("Expected restoring a live order to be a no-op, got changed=%v err=%v", changed, err)
+			}
+
+			if _, err := repo.Delete(ctx, deleted.ID, 0); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			purged, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+			if err != nil || purged != 0 {
+				t.Errorf("Expected nothing deleted over an hour ago, got %d (err %v)", purged, err)
+			}
+			purged, err = repo.Purge(ctx, time.Now().Add(time.Second))
+			if err != nil || purged != 1 {
+				t.Fatalf("Expected 1 order purged, got %d (err %v)", purged, err)
+			}
+			if orders, _ := repo.List(ctx, true); len(orders) != 1 || orders[0].ID != kept.ID {
+				t.Errorf("Expected only order %d to remain, got %+v", kept.ID, orders)
+			}
+			if _, _, err := repo.Restore(ctx, deleted.ID, 0); !errors.Is(err, ErrOrderNotFound) {
+				t.Errorf("Expected purged order not to be restorable, got %v", err)
+			}
+
+			replayed, err := repo.CreateIdempotent(ctx, newTestOrder("customer-1"), "key-1", "hash-1")
+			if err != nil || replayed {
+				t.Errorf("Expected the idempotency key to be released by the purge, got replayed=%v err=%v", replayed, err)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_ListByCustomerAndFindByID(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			own := newTestOrder("customer-1")
+			other := newTestOrder("customer-2")
+			for _, order := range []*domain.Order{own, other} {
+				if err := repo.Create(ctx, order); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+
+			orders, err := repo.ListByCustomer(ctx, "customer-1")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(orders) != 1 || orders[0].ID != own.ID {
+				t.Fatalf("Expected only order %d, got %+v", own.ID, orders)
+			}
+			if len(orders[0].Items) != 2 {
+				t.Errorf("Expected 2 items, got %d", len(orders[0].Items))
+			}
+
+			found, err := repo.FindByID(ctx, other.ID)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if found.CustomerID != "customer-2" || len(found.Items) != 2 {
+				t.Errorf("Expected order of customer-2 with 2 items, got %+v", found)
+			}
+
+			if _, err := repo.FindByID(ctx, other.ID+100); !errors.Is(err, ErrOrderNotFound) {
+				t.Errorf("Expected ErrOrderNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_ListInBatches(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, customerID := range []string{"customer-1", "customer-2", "customer-1", "customer-1", "customer-1"} {
+				if err := repo.Create(ctx, newTestOrder(customerID)); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+
+			var batches [][]uint
+			err := repo.ListInBatches(ctx, "customer-1", 2, func(orders []domain.Order) error {
+				var ids []uint
+				for _, order := range orders {
+					if len(order.Items) != 2 {
+						t.Errorf("Expected order %d to have 2 items, got %d", order.ID, len(order.Items))
+					}
+					ids = append(ids, order.ID)
+				}
+				batches = append(batches, ids)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if want := [][]uint{{1, 3}, {4, 5}}; !reflect.DeepEqual(batches, want) {
+				t.Errorf("Expected batches %v, got %v", want, batches)
+			}
+
+			stop := errors.New("stop")
+			calls := 0
+			err = repo.ListInBatches(ctx, "", 2, func([]domain.Order) error {
+				calls++
+				return stop
+			})
+			if !errors.Is(err, stop) || calls != 1 {
+				t.Errorf("Expected to stop after the first batch, got %v after %d calls", err, calls)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_Stats(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			// customer-1 orders amount to 2499 each, the customer-2 one to 1000.
+			cheap := newTestOrder("customer-2")
+			cheap.Items = []domain.OrderItem{{ProductID: "product-3", Quantity: 4, UnitPriceCents: 250}}
+			cheap.CalculateAmount()
+			for _, order := range []*domain.Order{newTestOrder("customer-1"), newTestOrder("customer-1"), cheap} {
+				if err := repo.Create(ctx, order); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+			if _, _, err := repo.UpdateStatus(ctx, cheap.ID, domain.OrderStatusConfirmed, 0); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			today := time.Now().UTC().Format(domain.ReportDayLayout)
+
+			tests := []struct {
+				name   string
+				filter domain.ReportFilter
+				want   []domain.OrderStats
+			}{
+				{
+					name:   "by customer",
+					filter: domain.ReportFilter{GroupBy: domain.ReportGroupByCustomer},
+					want: []domain.OrderStats{
+						{Key: "customer-1", Count: 2, TotalAmountCents: 4998, AverageAmountCents: 2499},
+						{Key: "customer-2", Count: 1, TotalAmountCents: 1000, AverageAmountCents: 1000},
+					},
+				},
+				{
+					name:   "by status",
+					filter: domain.ReportFilter{GroupBy: domain.ReportGroupByStatus},
+					want: []domain.OrderStats{
+						{Key: domain.OrderStatusConfirmed, Count: 1, TotalAmountCents: 1000, AverageAmountCents: 1000},
+						{Key: domain.OrderStatusPending, Count: 2, TotalAmountCents: 4998, AverageAmountCents: 2499},
+					},
+				},
+				{
+					name:   "by day",
+					filter: domain.ReportFilter{GroupBy: domain.ReportGroupByDay, From: time.Now().Add(-time.Hour)},
+					want:   []domain.OrderStats{{Key: today, Count: 3, TotalAmountCents: 5998, AverageAmountCents: 5998.0 / 3}},
+				},
+				{
+					name:   "by customer for one customer",
+					filter: domain.ReportFilter{GroupBy: domain.ReportGroupByCustomer, CustomerID: "customer-2"},
+					want:   []domain.OrderStats{{Key: "customer-2", Count: 1, TotalAmountCents: 1000, AverageAmountCents: 1000}},
+				},
+				{
+					name:   "before every order",
+					filter: domain.ReportFilter{GroupBy: domain.ReportGroupByDay, To: time.Now().Add(-time.Hour)},
+					want:   []domain.OrderStats{},
+				},
+			}
+			for _, tt := range tests {
+				got, err := repo.Stats(ctx, tt.filter)
+				if err != nil {
+					t.Fatalf("%s: expected no error, got %v", tt.name, err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("%s: expected %+v, got %+v", tt.name, tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderRepository_CanceledContext(t *testing.T) {
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			if err := repo.Create(ctx, newTestOrder("customer-1")); !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected Create to fail with context.Canceled, got %v", err)
+			}
+			if _, err := repo.CreateIdempotent(ctx, newTestOrder("customer-1"), "key-1", "hash-1"); !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected CreateIdempotent to fail with context.Canceled, got %v", err)
+			}
+			if _, err := repo.List(ctx, false); !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected List to fail with context.Canceled, got %v", err)
+			}
+			if _, _, err := repo.UpdateStatus(ctx, 1, domain.OrderStatusConfirmed, 0); !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected UpdateStatus to fail with context.Canceled, got %v", err)
+			}
+
+			orders, err := repo.List(context.Background(), false)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(orders) != 0 {
+				t.Errorf("Expected canceled calls to store nothing, got %d orders", len(orders))
+			}
+		})
+	}
+}
+
+func TestMemoryOrderRepository_ProcessPendingDoesNotBlockWhilePublishing(t *testing.T) {
+	repo := NewMemoryOrderRepository()
+	ctx := context.Background()
+	for _, customerID := range []string{"customer-1", "customer-2"} {
+		if err := repo.Create(ctx, newTestOrder(customerID)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	publishing := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := repo.ProcessPending(ctx, 10, func(msg outbox.Message) error {
+			if msg.ID == 1 {
+				close(publishing)
+				<-release
+			}
+			return nil
+		})
+		done <- err
+	}()
+
+	// A slow broker must not stall the repository.
+	<-publishing
+	if err := repo.Create(ctx, newTestOrder("customer-3")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.FindByID(ctx, 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The two published messages are dropped; the one created meanwhile is kept.
+	if len(repo.outbox) != 1 || repo.outbox[0].ID != 3 {
+		t.Fatalf("Expected only message 3 to be pending, got %+v", repo.outbox)
+	}
+	failure := errors.New("broker down")
+	if published, err := repo.ProcessPending(ctx, 10, func(outbox.Message) error { return failure }); published != 0 || !errors.Is(err, failure) {
+		t.Fatalf("Expected the failure to be returned, got %d, %v", published, err)
+	}
+	if len(repo.outbox) != 1 || repo.outbox[0].Attempts != 1 || repo.outbox[0].LastError != failure.Error() {
+		t.Errorf("Expected the failure to be recorded on message 3, got %+v", repo.outbox)
+	}
+}
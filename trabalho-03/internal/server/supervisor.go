@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type namedServer struct {
+	name    string
+	server  *http.Server
+	serving atomic.Bool
+}
+
+type closer struct {
+	name string
+	fn   func() error
+}
+
+// Supervisor runs the HTTP listeners and background workers of the process and
+// shuts them down together: when the context is cancelled or any listener
+// fails, listeners stop accepting connections and drain in-flight requests
+// until the shutdown timeout, workers are cancelled and awaited, and closers
+// (database pool, broker connection) run last, in reverse registration order.
+type Supervisor struct {
+	shutdownTimeout time.Duration
+	servers         []*namedServer
+	workers         []func(ctx context.Context)
+	closers         []closer
+}
+
+func NewSupervisor(shutdownTimeout time.Duration) *Supervisor {
+	return &Supervisor{shutdownTimeout: shutdownTimeout}
+}
+
+func (s *Supervisor) AddServer(name string, server *http.Server) {
+	s.servers = append(s.servers, &namedServer{name: name, server: server})
+}
+
+// Go registers a background worker. Its context is cancelled at shutdown.
+func (s *Supervisor) Go(worker func(ctx context.Context)) {
+	s.workers = append(s.workers, worker)
+}
+
+func (s *Supervisor) OnShutdown(name string, fn func() error) {
+	s.closers = append(s.closers, closer{name: name, fn: fn})
+}
+
+// Ready reports an error unless every listener is serving.
+func (s *Supervisor) Ready(ctx context.Context) error {
+	for _, ns := range s.servers {
+		if !ns.serving.Load() {
+			return fmt.Errorf("%s server is not serving", ns.name)
+		}
+	}
+	return nil
+}
+
+func (s *Supervisor) Run(ctx context.Context) error {
+	workerCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
+
+	var workers sync.WaitGroup
+	for _, worker := range s.workers {
+		workers.Add(1)
+		go func(worker func(ctx context.Context)) {
+			defer workers.Done()
+			worker(workerCtx)
+		}(worker)
+	}
+
+	var runErr error
+	serveErrs := make(chan error, len(s.servers))
+	for _, ns := range s.servers {
+		listener, err := net.Listen("tcp", ns.server.Addr)
+		if err != nil {
+			runErr = fmt.Errorf("%s server: %w", ns.name, err)
+			break
+		}
+
+		log.Printf("%s server listening on %s", ns.name, listener.Addr())
+		ns.serving.Store(true)
+		go func(ns *namedServer) {
+			err := ns.server.Serve(listener)
+			ns.serving.Store(false)
+			if !errors.Is(err, http.ErrServerClosed) {
+				serveErrs <- fmt.Errorf("%s server: %w", ns.name, err)
+			}
+		}(ns)
+	}
+
+	if runErr == nil {
+		select {
+		case <-ctx.Done():
+			log.Println("Shutdown signal received")
+		case runErr = <-serveErrs:
+		}
+	}
+	if runErr != nil {
+		log.Println("Shutting down after listener failure:", runErr)
+	}
+
+	shutdownErr := s.shutdown(cancelWorkers, &workers)
+	if runErr != nil {
+		return runErr
+	}
+	return shutdownErr
+}
+
+func (s *Supervisor) shutdown(cancelWorkers context.CancelFunc, workers *sync.WaitGroup) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	errs := make([]error, len(s.servers))
+	var wg sync.WaitGroup
+	for i, ns := range s.servers {
+		ns.serving.Store(false)
+		wg.Add(1)
+		go func(i int, ns *namedServer) {
+			defer wg.Done()
+			if err := ns.server.Shutdown(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s server did not drain in time: %w", ns.name, err)
+				ns.server.Close()
+			}
+		}(i, ns)
+	}
+	wg.Wait()
+
+	cancelWorkers()
+	workers.Wait()
+
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		if err := s.closers[i].fn(); err != nil {
+			errs = append(errs, fmt.Errorf("closing %s: %w", s.closers[i].name, err))
+		}
+	}
+
+	log.Println("Shutdown complete")
+	return errors.Join(errs...)
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to reserve a port: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func TestSupervisor_DrainsInFlightRequestsBeforeClosing(t *testing.T) {
+	addr := freeAddr(t)
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		io.WriteString(w, "done")
+	})
+
+	var closed, workerStopped bool
+	supervisor := NewSupervisor(5 * time.Second)
+	supervisor.AddServer("test", &http.Server{Addr: addr, Handler: handler})
+	supervisor.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		workerStopped = true
+	})
+	supervisor.OnShutdown("resource", func() error {
+		if !workerStopped {
+			t.Error("Expected workers to stop before closers run")
+		}
+		closed = true
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- supervisor.Run(ctx) }()
+
+	respBody := make(chan string, 1)
+	go func() {
+		for i := 0; i < 50; i++ {
+			resp, err := http.Get("http://" + addr)
+			if err != nil {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			respBody <- string(body)
+			return
+		}
+		respBody <- ""
+	}()
+
+	<-started
+	if err := supervisor.Ready(context.Background()); err != nil {
+		t.Errorf("Expected supervisor to be ready, got %v", err)
+	}
+	cancel()
+
+	if body := <-respBody; body != "done" {
+		t.Errorf("Expected in-flight request to complete, got %q", body)
+	}
+	if err := <-runErr; err != nil {
+		t.Errorf("Expected clean shutdown, got %v", err)
+	}
+	if !closed {
+		t.Error("Expected closers to run")
+	}
+	if err := supervisor.Ready(context.Background()); err == nil {
+		t.Error("Expected supervisor not to be ready after shutdown")
+	}
+}
@@ -0,0 +1,97 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "trabalho-03"
+
+// Transport names used as the "transport" attribute of spans and metrics.
+const (
+	TransportREST    = "rest"
+	TransportGRPC    = "grpc"
+	TransportGraphQL = "graphql"
+)
+
+// The global meter forwards to the provider installed by Setup, so the RED
+// instruments can be created before it runs.
+var (
+	meter = otel.Meter(instrumentationName)
+
+	requestCount, _ = meter.Int64Counter("orders.requests",
+		metric.WithDescription("Requests handled, by transport and operation."),
+		metric.WithUnit("{request}"))
+	errorCount, _ = meter.Int64Counter("orders.request.errors",
+		metric.WithDescription("Requests that failed on the server side, by transport and operation."),
+		metric.WithUnit("{request}"))
+	requestDuration, _ = meter.Float64Histogram("orders.request.duration",
+		metric.WithDescription("Request latency, by transport and operation."),
+		metric.WithUnit("s"))
+)
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Request is one request being served by a transport: a server span plus the
+// rate, errors and duration metrics recorded when it ends.
+type Request struct {
+	ctx       context.Context
+	span      trace.Span
+	start     time.Time
+	transport string
+	operation string
+}
+
+// SetMetricOperation labels the metrics with operation instead of the span
+// name. Transports whose span name comes from the client use it to keep the
+// number of metric series bounded.
+func (r *Request) SetMetricOperation(operation string) {
+	r.operation = operation
+}
+
+// StartRequest continues the trace propagated in carrier (if any) and starts
+// the server span for operation.
+func StartRequest(ctx context.Context, transport, operation string, carrier propagation.TextMapCarrier) (context.Context, *Request) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+	ctx, span := Tracer().Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("transport", transport)),
+	)
+	return ctx, &Request{ctx: ctx, span: span, start: time.Now(), transport: transport, operation: operation}
+}
+
+// End records the outcome. status is the transport's result code (HTTP status,
+// gRPC code or "ok"/"error"); err is non-nil only for server-side failures.
+func (r *Request) End(status string, err error) {
+	attrs := metric.WithAttributes(
+		attribute.String("transport", r.transport),
+		attribute.String("operation", r.operation),
+		attribute.String("status", status),
+	)
+	requestCount.Add(r.ctx, 1, attrs)
+	requestDuration.Record(r.ctx, time.Since(r.start).Seconds(), attrs)
+
+	r.span.SetAttributes(attribute.String("status", status))
+	if err != nil {
+		errorCount.Add(r.ctx, 1, attrs)
+	}
+	EndSpan(r.span, err)
+}
+
+// EndSpan marks span as failed when err is non-nil and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,84 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRequest_RecordsSpanAndREDMetrics(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	headers := http.Header{}
+	headers.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+
+	ctx, request := StartRequest(context.Background(), TransportREST, "POST /order", propagation.HeaderCarrier(headers))
+	_, child := Tracer().Start(ctx, "OrderUseCase.CreateOrder")
+	child.End()
+	request.End("500", errors.New("boom"))
+
+	_, succeeded := StartRequest(context.Background(), TransportREST, "POST /order", propagation.HeaderCarrier(http.Header{}))
+	succeeded.End("201", nil)
+
+	ended := spans.Ended()
+	if len(ended) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(ended))
+	}
+	server, usecase := ended[1], ended[0]
+	if server.SpanKind() != trace.SpanKindServer || server.Name() != "POST /order" {
+		t.Errorf("Expected server span POST /order, got %s %s", server.SpanKind(), server.Name())
+	}
+	if server.Parent().TraceID().String() != "0af7651916cd43dd8448eb211c80319c" {
+		t.Errorf("Expected the propagated trace to continue, got trace %s", server.Parent().TraceID())
+	}
+	if usecase.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Error("Expected the use case span to be a child of the server span")
+	}
+	if server.Status().Code != codes.Error {
+		t.Errorf("Expected server span status Error, got %v", server.Status().Code)
+	}
+
+	var metrics metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &metrics); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+	sums := map[string]int64{}
+	for _, scope := range metrics.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, point := range data.DataPoints {
+					sums[m.Name] += point.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, point := range data.DataPoints {
+					sums[m.Name] += int64(point.Count)
+					if operation, _ := point.Attributes.Value(attribute.Key("operation")); operation.AsString() != "POST /order" {
+						t.Errorf("Expected operation attribute POST /order, got %q", operation.AsString())
+					}
+				}
+			}
+		}
+	}
+	want := map[string]int64{"orders.requests": 2, "orders.request.errors": 1, "orders.request.duration": 2}
+	for name, count := range want {
+		if sums[name] != count {
+			t.Errorf("Expected %s = %d, got %d", name, count, sums[name])
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"trabalho-03/internal/auth"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/telemetry"
+	"trabalho-03/internal/usecase/validation"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// exportBatchSize is the number of orders loaded per query while exporting.
+const exportBatchSize = 500
+
+// ImportRow is one record of a bulk import. Line locates it in the input (a
+// line number for text formats, the message index for streams); Err is set
+// when the record could not be decoded into Order.
+type ImportRow struct {
+	Line  int
+	Order *domain.Order
+	Err   error
+}
+
+// ImportFailure explains why the record at Line was not imported.
+type ImportFailure struct {
+	Line   int               `json:"line"`
+	Error  string            `json:"error"`
+	Fields validation.Errors `json:"fields,omitempty"`
+}
+
+type ImportReport struct {
+	Total    int             `json:"total"`
+	Imported int             `json:"imported"`
+	Failed   int             `json:"failed"`
+	Failures []ImportFailure `json:"failures"`
+}
+
+// ImportOrders creates an order for each row returned by next until it returns
+// io.EOF. Rows are validated and stored one at a time, so a bad row is only
+// recorded in the report and does not affect the others. Any other error from
+// next or the repository (including ctx being done) stops the import and is
+// returned together with the report of the rows processed so far.
+func (uc *OrderUseCase) ImportOrders(ctx context.Context, next func() (ImportRow, error)) (report *ImportReport, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "OrderUseCase.ImportOrders")
+	defer func() {
+		if report != nil {
+			span.SetAttributes(
+				attribute.Int("import.imported", report.Imported),
+				attribute.Int("import.failed", report.Failed),
+			)
+		}
+		telemetry.EndSpan(span, err)
+	}()
+
+	if _, ok := auth.ClaimsFromContext(ctx); !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	report = &ImportReport{Failures: []ImportFailure{}}
+	for {
+		row, err := next()
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Total++
+
+		if row.Err == nil {
+			err = uc.CreateOrder(ctx, row.Order, "")
+			if err == nil {
+				report.Imported++
+				continue
+			}
+			if !errors.Is(err, auth.ErrForbidden) && !errors.As(err, new(validation.Errors)) {
+				return report, err
+			}
+			row.Err = err
+		}
+		report.Failed++
+		report.Failures = append(report.Failures, newImportFailure(row))
+	}
+}
+
+func newImportFailure(row ImportRow) ImportFailure {
+	var validationErrs validation.Errors
+	if errors.As(row.Err, &validationErrs) {
+		return ImportFailure{Line: row.Line, Error: "validation failed", Fields: validationErrs}
+	}
+	return ImportFailure{Line: row.Line, Error: row.Err.Error()}
+}
+
+// ExportOrders hands the orders the actor can see to fn in batches, in ID
+// order, without loading them all at once. It stops at the first error.
+func (uc *OrderUseCase) ExportOrders(ctx context.Context, fn func([]domain.Order) error) (err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "OrderUseCase.ExportOrders")
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	actor, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return auth.ErrUnauthenticated
+	}
+	customerID := actor.CustomerID
+	if actor.IsAdmin() {
+		customerID = ""
+	}
+	return uc.orderRepo.ListInBatches(ctx, customerID, exportBatchSize, fn)
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/event"
+	"trabalho-03/internal/repository"
+)
+
+// rowsOf returns an ImportOrders source yielding rows and then end.
+func rowsOf(end error, rows ...ImportRow) func() (ImportRow, error) {
+	return func() (ImportRow, error) {
+		if len(rows) == 0 {
+			return ImportRow{}, end
+		}
+		row := rows[0]
+		rows = rows[1:]
+		return row, nil
+	}
+}
+
+func TestOrderUseCase_ImportOrdersReportsFailedRows(t *testing.T) {
+	repo := repository.NewMemoryOrderRepository()
+	uc := NewOrderUseCase(repo, event.NewBus())
+
+	invalid := newOrder("customer-1")
+	invalid.Status = "lost"
+	report, err := uc.ImportOrders(customerContext("customer-1"), rowsOf(io.EOF,
+		ImportRow{Line: 2, Order: newOrder("customer-1")},
+		ImportRow{Line: 3, Order: invalid},
+		ImportRow{Line: 4, Err: errors.New("invalid JSON")},
+		ImportRow{Line: 5, Order: newOrder("customer-2")},
+		ImportRow{Line: 6, Order: newOrder("customer-1")},
+	))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if report.Total != 5 || report.Imported != 2 || report.Failed != 3 {
+		t.Errorf("Expected 5 rows with 2 imported and 3 failed, got %+v", report)
+	}
+	wantLines := []int{3, 4, 5}
+	for i, failure := range report.Failures {
+		if failure.Line != wantLines[i] {
+			t.Errorf("Expected failure %d on line %d, got %+v", i, wantLines[i], failure)
+		}
+	}
+	if len(report.Failures) == 3 && (len(report.Failures[0].Fields) != 1 || report.Failures[0].Fields[0].Field != "status") {
+		t.Errorf("Expected a status field error on line 3, got %+v", report.Failures[0])
+	}
+
+	orders, _ := repo.List(context.Background(), false)
+	if len(orders) != 2 {
+		t.Errorf("Expected 2 stored orders, got %d", len(orders))
+	}
+}
+
+func TestOrderUseCase_ImportOrdersStopsOnReadErrors(t *testing.T) {
+	uc := NewOrderUseCase(repository.NewMemoryOrderRepository(), event.NewBus())
+	readErr := errors.New("connection reset")
+
+	report, err := uc.ImportOrders(adminContext(context.Background()),
+		rowsOf(readErr, ImportRow{Line: 1, Order: newOrder("customer-1")}))
+	if !errors.Is(err, readErr) {
+		t.Errorf("Expected the read error, got %v", err)
+	}
+	if report == nil || report.Imported != 1 {
+		t.Errorf("Expected the rows read before the error to be reported, got %+v", report)
+	}
+}
+
+func TestOrderUseCase_ExportOrdersIsScopedToTheCustomer(t *testing.T) {
+	uc := NewOrderUseCase(repository.NewMemoryOrderRepository(), event.NewBus())
+	for _, customerID := range []string{"customer-1", "customer-2", "customer-1"} {
+		if err := uc.CreateOrder(adminContext(context.Background()), newOrder(customerID), ""); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	var exported []domain.Order
+	err := uc.ExportOrders(customerContext("customer-1"), func(orders []domain.Order) error {
+		exported = append(exported, orders...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(exported) != 2 || exported[0].ID != 1 || exported[1].ID != 3 {
+		t.Errorf("Expected orders 1 and 3, got %+v", exported)
+	}
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"trabalho-03/internal/auth"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/repository"
+	"trabalho-03/internal/telemetry"
+	"trabalho-03/internal/usecase/validation"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type CustomerUseCase struct {
+	customerRepo repository.CustomerRepository
+}
+
+func NewCustomerUseCase(customerRepo repository.CustomerRepository) *CustomerUseCase {
+	return &CustomerUseCase{customerRepo: customerRepo}
+}
+
+// GetCustomers returns the customers among ids the caller may see: every one
+// for admins, only themselves for customers. Unknown or hidden ids are left out.
+func (uc *CustomerUseCase) GetCustomers(ctx context.Context, ids []string) (customers []domain.Customer, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "CustomerUseCase.GetCustomers",
+		trace.WithAttributes(attribute.Int("customer.count", len(ids))))
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	actor, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+	if !actor.IsAdmin() {
+		visible := ids[:0:0]
+		for _, id := range ids {
+			if actor.CanAccess(id) {
+				visible = append(visible, id)
+			}
+		}
+		ids = visible
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return uc.customerRepo.FindByIDs(ctx, ids)
+}
+
+// UpsertCustomer creates or updates a customer profile. Only admins may call it.
+func (uc *CustomerUseCase) UpsertCustomer(ctx context.Context, customer *domain.Customer) (err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "CustomerUseCase.UpsertCustomer")
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	actor, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return auth.ErrUnauthenticated
+	}
+	if !actor.IsAdmin() {
+		return auth.ErrForbidden
+	}
+	if err := validation.ValidateCustomer(customer); err != nil {
+		return err
+	}
+	return uc.customerRepo.Upsert(ctx, customer)
+}
@@ -1,22 +1,258 @@
 package usecase
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"trabalho-03/internal/auth"
 	"trabalho-03/internal/domain"
+	"trabalho-03/internal/event"
 	"trabalho-03/internal/repository"
+	"trabalho-03/internal/telemetry"
+	"trabalho-03/internal/usecase/validation"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type OrderUseCase struct {
-	orderRepo *repository.OrderRepository
+	orderRepo repository.OrderRepository
+	eventBus  *event.Bus
+}
+
+func NewOrderUseCase(orderRepo repository.OrderRepository, eventBus *event.Bus) *OrderUseCase {
+	return &OrderUseCase{orderRepo: orderRepo, eventBus: eventBus}
+}
+
+// CreateOrder stores a new order. When idempotencyKey is set, retries with the
+// same key and payload return the originally created order instead of inserting
+// a duplicate; reusing the key for a different payload fails with
+// repository.ErrIdempotencyKeyConflict. Invalid input fails with validation.Errors,
+// and customers creating orders for someone else fail with auth.ErrForbidden.
+//
+// The caller is identified by the auth.Claims stored in ctx; every use case
+// method fails with auth.ErrUnauthenticated when there are none.
+func (uc *OrderUseCase) CreateOrder(ctx context.Context, order *domain.Order, idempotencyKey string) (err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "OrderUseCase.CreateOrder")
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	actor, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return auth.ErrUnauthenticated
+	}
+	order.CalculateAmount()
+	if err := validation.ValidateNewOrder(order, idempotencyKey); err != nil {
+		return err
+	}
+	if !actor.CanAccess(order.CustomerID) {
+		return auth.ErrForbidden
+	}
+
+	if idempotencyKey == "" {
+		if err := uc.orderRepo.Create(ctx, order); err != nil {
+			return err
+		}
+	} else {
+		hash, err := requestHash(order)
+		if err != nil {
+			return err
+		}
+		replayed, err := uc.orderRepo.CreateIdempotent(ctx, order, idempotencyKey, hash)
+		if err != nil {
+			return err
+		}
+		span.SetAttributes(attribute.Bool("order.idempotency_replayed", replayed))
+		if replayed {
+			return nil
+		}
+	}
+
+	span.SetAttributes(attribute.Int64("order.id", int64(order.ID)))
+	uc.eventBus.Publish(event.OrderEvent{Type: domain.EventOrderCreated, Order: *order})
+	return nil
 }
 
-func NewOrderUseCase(orderRepo *repository.OrderRepository) *OrderUseCase {
-	return &OrderUseCase{orderRepo: orderRepo}
+// ListOrders returns every order to admins and only their own orders to customers.
+// Soft-deleted orders are included only when includeDeleted is set, which is
+// reserved to admins.
+func (uc *OrderUseCase) ListOrders(ctx context.Context, includeDeleted bool) (orders []domain.Order, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "OrderUseCase.ListOrders",
+		trace.WithAttributes(attribute.Bool("order.include_deleted", includeDeleted)))
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	actor, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+	if actor.IsAdmin() {
+		return uc.orderRepo.List(ctx, includeDeleted)
+	}
+	if includeDeleted {
+		return nil, auth.ErrForbidden
+	}
+	return uc.orderRepo.ListByCustomer(ctx, actor.CustomerID)
+}
+
+// GetOrder returns one order with its current version. Orders of other
+// customers are reported as repository.ErrOrderNotFound, as in checkAccess.
+func (uc *OrderUseCase) GetOrder(ctx context.Context, id uint) (order *domain.Order, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "OrderUseCase.GetOrder",
+		trace.WithAttributes(attribute.Int64("order.id", int64(id))))
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	actor, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+	order, err = uc.orderRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanAccess(order.CustomerID) {
+		return nil, repository.ErrOrderNotFound
+	}
+	return order, nil
 }
 
-func (uc *OrderUseCase) CreateOrder(order *domain.Order) error {
-	return uc.orderRepo.Create(order)
+// UpdateOrderStatus changes the status of an order the actor can access. Orders
+// of other customers are reported as repository.ErrOrderNotFound so their
+// existence is not revealed. A non-zero version is the version the client last
+// saw; repository.ErrVersionConflict is returned when the order has changed since.
+func (uc *OrderUseCase) UpdateOrderStatus(ctx context.Context, id uint, status string, version uint) (order *domain.Order, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "OrderUseCase.UpdateOrderStatus",
+		trace.WithAttributes(attribute.Int64("order.id", int64(id))))
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	actor, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+	if err := validation.ValidateStatus(status); err != nil {
+		return nil, err
+	}
+	if err := uc.checkAccess(ctx, actor, id); err != nil {
+		return nil, err
+	}
+
+	order, changed, err := uc.orderRepo.UpdateStatus(ctx, id, status, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		uc.eventBus.Publish(event.OrderEvent{Type: domain.EventOrderStatusChanged, Order: *order})
+	}
+	return order, nil
 }
 
-func (uc *OrderUseCase) ListOrders() ([]domain.Order, error) {
-	return uc.orderRepo.List()
+// DeleteOrder soft-deletes an order the actor can access; version works as in
+// UpdateOrderStatus. Deleted orders disappear from listings and reports until
+// restored, and are purged for good by PurgeJob.
+func (uc *OrderUseCase) DeleteOrder(ctx context.Context, id uint, version uint) (order *domain.Order, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "OrderUseCase.DeleteOrder",
+		trace.WithAttributes(attribute.Int64("order.id", int64(id))))
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	actor, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+	if err := uc.checkAccess(ctx, actor, id); err != nil {
+		return nil, err
+	}
+
+	order, err = uc.orderRepo.Delete(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.eventBus.Publish(event.OrderEvent{Type: domain.EventOrderDeleted, Order: *order})
+	return order, nil
+}
+
+// RestoreOrder undoes DeleteOrder. Only admins may call it, since deleted
+// orders are hidden from customers.
+func (uc *OrderUseCase) RestoreOrder(ctx context.Context, id uint, version uint) (order *domain.Order, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "OrderUseCase.RestoreOrder",
+		trace.WithAttributes(attribute.Int64("order.id", int64(id))))
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	actor, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+	if !actor.IsAdmin() {
+		return nil, auth.ErrForbidden
+	}
+
+	order, changed, err := uc.orderRepo.Restore(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		uc.eventBus.Publish(event.OrderEvent{Type: domain.EventOrderRestored, Order: *order})
+	}
+	return order, nil
+}
+
+// checkAccess reports orders of other customers as repository.ErrOrderNotFound,
+// so customers cannot probe for the existence of orders they cannot see.
+func (uc *OrderUseCase) checkAccess(ctx context.Context, actor *auth.Claims, id uint) error {
+	if actor.IsAdmin() {
+		return nil
+	}
+	existing, err := uc.orderRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !actor.CanAccess(existing.CustomerID) {
+		return repository.ErrOrderNotFound
+	}
+	return nil
+}
+
+// OrderStats aggregates orders for reports. Customers only get figures about
+// their own orders; filter.CustomerID is ignored for them.
+func (uc *OrderUseCase) OrderStats(ctx context.Context, filter domain.ReportFilter) (stats []domain.OrderStats, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "OrderUseCase.OrderStats",
+		trace.WithAttributes(attribute.String("report.group_by", filter.GroupBy)))
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	actor, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+	if err := validation.ValidateReportFilter(filter); err != nil {
+		return nil, err
+	}
+	if !actor.IsAdmin() {
+		filter.CustomerID = actor.CustomerID
+	}
+	return uc.orderRepo.Stats(ctx, filter)
+}
+
+// requestHash fingerprints the client-controlled fields of a new order.
+func requestHash(order *domain.Order) (string, error) {
+	type item struct {
+		ProductID      string
+		Quantity       int32
+		UnitPriceCents int64
+	}
+	fingerprint := struct {
+		CustomerID string
+		Status     string
+		Items      []item
+	}{CustomerID: order.CustomerID, Status: order.Status}
+	for _, i := range order.Items {
+		fingerprint.Items = append(fingerprint.Items, item{i.ProductID, i.Quantity, i.UnitPriceCents})
+	}
+
+	data, err := json.Marshal(fingerprint)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
 }
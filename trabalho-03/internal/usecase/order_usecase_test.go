@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+	"trabalho-03/internal/auth"
+	"trabalho-03/internal/domain"
+	"trabalho-03/internal/event"
+	"trabalho-03/internal/repository"
+	"trabalho-03/internal/usecase/validation"
+)
+
+// blockingRepository never answers List before ctx is done, like a slow query.
+type blockingRepository struct {
+	repository.OrderRepository
+}
+
+func (blockingRepository) List(ctx context.Context, includeDeleted bool) ([]domain.Order, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func adminContext(ctx context.Context) context.Context {
+	return auth.WithClaims(ctx, &auth.Claims{Role: auth.RoleAdmin})
+}
+
+func customerContext(customerID string) context.Context {
+	return auth.WithClaims(context.Background(), &auth.Claims{Role: auth.RoleCustomer, CustomerID: customerID})
+}
+
+func newOrder(customerID string) *domain.Order {
+	return &domain.Order{
+		CustomerID: customerID,
+		Status:     domain.OrderStatusPending,
+		Items:      []domain.OrderItem{{ProductID: "product-1", Quantity: 1, UnitPriceCents: 500}},
+	}
+}
+
+func TestOrderUseCase_CanceledContextStoresAndPublishesNothing(t *testing.T) {
+	repo := repository.NewMemoryOrderRepository()
+	bus := event.NewBus()
+	events, unsubscribe := bus.Subscribe(1)
+	defer unsubscribe()
+	uc := NewOrderUseCase(repo, bus)
+
+	ctx, cancel := context.WithCancel(adminContext(context.Background()))
+	cancel()
+
+	if err := uc.CreateOrder(ctx, newOrder("customer-1"), ""); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := uc.UpdateOrderStatus(ctx, 1, domain.OrderStatusConfirmed, 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	select {
+	case evt := <-events:
+		t.Errorf("Expected no event, got %s", evt.Type)
+	default:
+	}
+	orders, _ := uc.ListOrders(adminContext(context.Background()), false)
+	if len(orders) != 0 {
+		t.Errorf("Expected no stored orders, got %d", len(orders))
+	}
+}
+
+func TestOrderUseCase_DeadlineStopsSlowRepository(t *testing.T) {
+	uc := NewOrderUseCase(blockingRepository{}, event.NewBus())
+
+	ctx, cancel := context.WithTimeout(adminContext(context.Background()), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := uc.ListOrders(ctx, false)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected ListOrders to return at the deadline, took %v", elapsed)
+	}
+}
+
+func TestOrderUseCase_CustomersOnlyAccessTheirOwnOrders(t *testing.T) {
+	uc := NewOrderUseCase(repository.NewMemoryOrderRepository(), event.NewBus())
+
+	own := newOrder("customer-1")
+	if err := uc.CreateOrder(customerContext("customer-1"), own, ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := uc.CreateOrder(customerContext("customer-2"), newOrder("customer-1"), ""); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("Expected auth.ErrForbidden, got %v", err)
+	}
+	if err := uc.CreateOrder(context.Background(), newOrder("customer-1"), ""); !errors.Is(err, auth.ErrUnauthenticated) {
+		t.Errorf("Expected auth.ErrUnauthenticated, got %v", err)
+	}
+
+	orders, err := uc.ListOrders(customerContext("customer-2"), false)
+	if err != nil || len(orders) != 0 {
+		t.Errorf("Expected no orders for customer-2, got %d (err %v)", len(orders), err)
+	}
+	_, err = uc.UpdateOrderStatus(customerContext("customer-2"), own.ID, domain.OrderStatusCancelled, 0)
+	if !errors.Is(err, repository.ErrOrderNotFound) {
+		t.Errorf("Expected repository.ErrOrderNotFound, got %v", err)
+	}
+
+	orders, err = uc.ListOrders(adminContext(context.Background()), false)
+	if err != nil || len(orders) != 1 {
+		t.Errorf("Expected admin to see 1 order, got %d (err %v)", len(orders), err)
+	}
+}
+
+func TestOrderUseCase_DeletedOrdersAreManagedByAdmins(t *testing.T) {
+	uc := NewOrderUseCase(repository.NewMemoryOrderRepository(), event.NewBus())
+	order := newOrder("customer-1")
+	if err := uc.CreateOrder(customerContext("customer-1"), order, ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := uc.DeleteOrder(customerContext("customer-2"), order.ID, 0); !errors.Is(err, repository.ErrOrderNotFound) {
+		t.Errorf("Expected repository.ErrOrderNotFound for another customer, got %v", err)
+	}
+	if _, err := uc.DeleteOrder(customerContext("customer-1"), order.ID, 0); err != nil {
+		t.Fatalf("Expected owner to delete the order, got %v", err)
+	}
+
+	if _, err := uc.ListOrders(customerContext("customer-1"), true); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("Expected auth.ErrForbidden listing deleted orders as a customer, got %v", err)
+	}
+	if _, err := uc.RestoreOrder(customerContext("customer-1"), order.ID, 0); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("Expected auth.ErrForbidden restoring as a customer, got %v", err)
+	}
+
+	admin := adminContext(context.Background())
+	if orders, err := uc.ListOrders(admin, true); err != nil || len(orders) != 1 {
+		t.Errorf("Expected admin to list the deleted order, got %d (err %v)", len(orders), err)
+	}
+	if _, err := uc.RestoreOrder(admin, order.ID, 0); err != nil {
+		t.Fatalf("Expected admin to restore the order, got %v", err)
+	}
+	if orders, err := uc.ListOrders(customerContext("customer-1"), false); err != nil || len(orders) != 1 {
+		t.Errorf("Expected the restored order to be listed again, got %d (err %v)", len(orders), err)
+	}
+}
+
+func TestOrderUseCase_OrderStatsAreScopedToTheCustomer(t *testing.T) {
+	uc := NewOrderUseCase(repository.NewMemoryOrderRepository(), event.NewBus())
+	for _, customerID := range []string{"customer-1", "customer-2", "customer-2"} {
+		if err := uc.CreateOrder(adminContext(context.Background()), newOrder(customerID), ""); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	byCustomer := domain.ReportFilter{GroupBy: domain.ReportGroupByCustomer}
+
+	stats, err := uc.OrderStats(adminContext(context.Background()), byCustomer)
+	if err != nil || len(stats) != 2 {
+		t.Errorf("Expected admin to get 2 groups, got %+v (err %v)", stats, err)
+	}
+
+	byCustomer.CustomerID = "customer-2"
+	stats, err = uc.OrderStats(customerContext("customer-1"), byCustomer)
+	if err != nil || len(stats) != 1 || stats[0].Key != "customer-1" || stats[0].Count != 1 {
+		t.Errorf("Expected only customer-1 figures, got %+v (err %v)", stats, err)
+	}
+
+	var validationErrs validation.Errors
+	_, err = uc.OrderStats(adminContext(context.Background()), domain.ReportFilter{GroupBy: "month"})
+	if !errors.As(err, &validationErrs) || validationErrs[0].Field != "group_by" {
+		t.Errorf("Expected a group_by validation error, got %v", err)
+	}
+	now := time.Now()
+	_, err = uc.OrderStats(adminContext(context.Background()), domain.ReportFilter{GroupBy: domain.ReportGroupByDay, From: now, To: now})
+	if !errors.As(err, &validationErrs) || validationErrs[0].Field != "to" {
+		t.Errorf("Expected a to validation error, got %v", err)
+	}
+}
+
+func TestOrderUseCase_CreateOrderRejectsHugeAmounts(t *testing.T) {
+	uc := NewOrderUseCase(repository.NewMemoryOrderRepository(), event.NewBus())
+
+	tests := map[string]struct {
+		items     []domain.OrderItem
+		wantField string
+	}{
+		"huge quantity": {[]domain.OrderItem{{ProductID: "p", Quantity: math.MaxInt32, UnitPriceCents: 500}}, "items[0].quantity"},
+		"huge price":    {[]domain.OrderItem{{ProductID: "p", Quantity: 1, UnitPriceCents: math.MaxInt64}}, "items[0].unit_price_cents"},
+		"huge total": {[]domain.OrderItem{
+			{ProductID: "p", Quantity: domain.MaxItemQuantity, UnitPriceCents: domain.MaxUnitPriceCents},
+			{ProductID: "q", Quantity: 1, UnitPriceCents: 1},
+		}, "amount_cents"},
+	}
+	for name, tt := range tests {
+		order := newOrder("customer-1")
+		order.Items = tt.items
+
+		var validationErrs validation.Errors
+		err := uc.CreateOrder(adminContext(context.Background()), order, "")
+		if !errors.As(err, &validationErrs) || validationErrs[0].Field != tt.wantField {
+			t.Errorf("%s: expected a %s validation error, got %v", name, tt.wantField, err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+	"trabalho-03/internal/repository"
+	"trabalho-03/internal/telemetry"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// PurgeJob permanently removes orders that have been soft-deleted for longer
+// than the retention period. It runs as the system, outside any request, so it
+// does not go through the auth checks of OrderUseCase.
+type PurgeJob struct {
+	orderRepo repository.OrderRepository
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewPurgeJob(orderRepo repository.OrderRepository, retention, interval time.Duration) *PurgeJob {
+	return &PurgeJob{orderRepo: orderRepo, retention: retention, interval: interval}
+}
+
+// Run purges once right away and then every interval until ctx is done.
+func (j *PurgeJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := j.PurgeExpired(ctx); err != nil {
+			log.Println("Order purge:", err)
+		} else if purged > 0 {
+			log.Printf("Order purge: removed %d order(s) deleted more than %s ago", purged, j.retention)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpired removes the orders deleted before now minus the retention
+// period and returns how many were removed.
+func (j *PurgeJob) PurgeExpired(ctx context.Context) (purged int64, err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "PurgeJob.PurgeExpired")
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	purged, err = j.orderRepo.Purge(ctx, time.Now().Add(-j.retention))
+	span.SetAttributes(attribute.Int64("order.purged", purged))
+	return purged, err
+}
@@ -0,0 +1,120 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"trabalho-03/internal/domain"
+)
+
+const maxIdempotencyKeyLength = 255
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors is returned by the use case when the input is invalid. Transports map
+// it to their own "invalid argument" representation.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fe := range e {
+		messages = append(messages, fe.Field+": "+fe.Message)
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+func (e Errors) ErrorKind() domain.Kind {
+	return domain.KindInvalid
+}
+
+func (e *Errors) add(field, format string, args ...any) {
+	*e = append(*e, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e Errors) orNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ValidateNewOrder checks a client-submitted order. AmountCents must already be
+// calculated from the items.
+func ValidateNewOrder(order *domain.Order, idempotencyKey string) error {
+	var errs Errors
+
+	if strings.TrimSpace(order.CustomerID) == "" {
+		errs.add("customer_id", "must not be empty")
+	}
+	if !domain.IsValidOrderStatus(order.Status) {
+		errs.add("status", "must be one of %s", strings.Join(domain.OrderStatuses, ", "))
+	}
+	if len(order.Items) == 0 {
+		errs.add("items", "must contain at least one item")
+	}
+	for i, item := range order.Items {
+		if strings.TrimSpace(item.ProductID) == "" {
+			errs.add(fmt.Sprintf("items[%d].product_id", i), "must not be empty")
+		}
+		if item.Quantity <= 0 {
+			errs.add(fmt.Sprintf("items[%d].quantity", i), "must be positive")
+		} else if item.Quantity > domain.MaxItemQuantity {
+			errs.add(fmt.Sprintf("items[%d].quantity", i), "must be at most %d", domain.MaxItemQuantity)
+		}
+		if item.UnitPriceCents < 0 {
+			errs.add(fmt.Sprintf("items[%d].unit_price_cents", i), "must not be negative")
+		} else if item.UnitPriceCents > domain.MaxUnitPriceCents {
+			errs.add(fmt.Sprintf("items[%d].unit_price_cents", i), "must be at most %d", domain.MaxUnitPriceCents)
+		}
+	}
+	if len(order.Items) > 0 && order.AmountCents <= 0 {
+		errs.add("amount_cents", "must be positive")
+	} else if order.AmountCents > domain.MaxOrderAmountCents {
+		errs.add("amount_cents", "must be at most %d", domain.MaxOrderAmountCents)
+	}
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		errs.add("idempotency_key", "must have at most %d characters", maxIdempotencyKeyLength)
+	}
+
+	return errs.orNil()
+}
+
+func ValidateStatus(status string) error {
+	var errs Errors
+	if !domain.IsValidOrderStatus(status) {
+		errs.add("status", "must be one of %s", strings.Join(domain.OrderStatuses, ", "))
+	}
+	return errs.orNil()
+}
+
+func ValidateCustomer(customer *domain.Customer) error {
+	var errs Errors
+	if strings.TrimSpace(customer.ID) == "" {
+		errs.add("id", "must not be empty")
+	}
+	if strings.TrimSpace(customer.Name) == "" {
+		errs.add("name", "must not be empty")
+	}
+	if address, err := mail.ParseAddress(customer.Email); err != nil || address.Address != customer.Email {
+		errs.add("email", "must be a valid e-mail address")
+	}
+	return errs.orNil()
+}
+
+func ValidateReportFilter(filter domain.ReportFilter) error {
+	var errs Errors
+	valid := false
+	for _, groupBy := range domain.ReportGroupings {
+		valid = valid || filter.GroupBy == groupBy
+	}
+	if !valid {
+		errs.add("group_by", "must be one of %s", strings.Join(domain.ReportGroupings, ", "))
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		errs.add("to", "must be after from")
+	}
+	return errs.orNil()
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ConvertLegacy brings a database created by GORM AutoMigrate, before the
+// versioned migrations, to the schema 0001 expects. Order totals move from
+// the float amount column to amount_cents, rounded to the nearest cent, and
+// amount is dropped; AutoMigrate had added an empty amount_cents column next
+// to it once amounts became cents. The other tables were created from the
+// same models as the migrations and are adopted as they are.
+func ConvertLegacy(tx *gorm.DB) error {
+	migrator := tx.Migrator()
+	if !migrator.HasTable("orders") || !migrator.HasColumn("orders", "amount") {
+		return nil
+	}
+
+	if !migrator.HasColumn("orders", "amount_cents") {
+		if err := tx.Exec("ALTER TABLE orders ADD COLUMN amount_cents BIGINT").Error; err != nil {
+			return fmt.Errorf("failed to add orders.amount_cents: %w", err)
+		}
+	}
+	// Orders created once amounts were cents have no amount and keep their
+	// amount_cents.
+	err := tx.Exec(`UPDATE orders SET amount_cents = CAST(ROUND(amount * 100) AS BIGINT)
+		WHERE amount IS NOT NULL AND (amount_cents IS NULL OR amount_cents = 0)`).Error
+	if err != nil {
+		return fmt.Errorf("failed to convert orders.amount to cents: %w", err)
+	}
+	if err := tx.Exec("ALTER TABLE orders DROP COLUMN amount").Error; err != nil {
+		return fmt.Errorf("failed to drop orders.amount: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+// Package migrations embeds the versioned SQL migrations, one directory per
+// database dialect. Files are named <version>_<name>.<up|down>.sql.
+package migrations
+
+import "embed"
+
+//go:embed postgres/*.sql sqlite/*.sql
+var FS embed.FS
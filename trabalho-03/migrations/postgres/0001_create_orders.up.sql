@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS orders (
+    id           BIGSERIAL PRIMARY KEY,
+    customer_id  TEXT,
+    amount_cents BIGINT,
+    status       TEXT,
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ,
+    deleted_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_deleted_at ON orders (deleted_at);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id               BIGSERIAL PRIMARY KEY,
+    order_id         BIGINT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_id       TEXT NOT NULL,
+    quantity         INTEGER NOT NULL,
+    unit_price_cents BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items (order_id);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key          VARCHAR(255) PRIMARY KEY,
+    request_hash VARCHAR(64) NOT NULL,
+    order_id     BIGINT NOT NULL,
+    response     BYTEA NOT NULL,
+    created_at   TIMESTAMPTZ
+);
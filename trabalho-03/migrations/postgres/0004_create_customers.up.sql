@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS customers (
+    id         VARCHAR(255) PRIMARY KEY,
+    name       TEXT NOT NULL,
+    email      TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
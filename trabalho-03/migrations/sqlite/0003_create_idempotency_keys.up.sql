@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key          TEXT PRIMARY KEY,
+    request_hash TEXT NOT NULL,
+    order_id     INTEGER NOT NULL,
+    response     BLOB NOT NULL,
+    created_at   DATETIME
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS customers (
+    id         TEXT PRIMARY KEY,
+    name       TEXT NOT NULL,
+    email      TEXT NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME
+);
@@ -0,0 +1,148 @@
+// Package orderclient is a Go client for the order service. The Client
+// interface is implemented over REST (NewREST), GraphQL (NewGraphQL) and the
+// gRPC-style HTTP/JSON endpoints (NewGRPC), so callers can switch transports
+// without touching their code. Failures reported by the service are returned
+// as *Error values that compare equal, under errors.Is, to the Err* sentinels.
+package orderclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Client is the order service API shared by every transport. A non-zero
+// version makes a call fail with ErrPrecondition when the order has changed
+// since the client read it at that version; zero skips the check.
+type Client interface {
+	CreateOrder(ctx context.Context, order NewOrder) (*Order, error)
+	ListOrders(ctx context.Context, options ListOptions) ([]Order, error)
+	UpdateOrderStatus(ctx context.Context, id uint, status string, version uint) (*Order, error)
+	DeleteOrder(ctx context.Context, id uint, version uint) error
+	RestoreOrder(ctx context.Context, id uint, version uint) (*Order, error)
+}
+
+// Order amounts are in cents. DeletedAt is set while the order is
+// soft-deleted, which only admins listing with IncludeDeleted get to see.
+type Order struct {
+	ID          uint       `json:"id"`
+	CustomerID  string     `json:"customer_id"`
+	Items       []Item     `json:"items"`
+	AmountCents int64      `json:"amount_cents"`
+	Status      string     `json:"status"`
+	Version     uint       `json:"version"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+type Item struct {
+	ProductID      string `json:"product_id"`
+	Quantity       int32  `json:"quantity"`
+	UnitPriceCents int64  `json:"unit_price_cents"`
+}
+
+// NewOrder is the input of CreateOrder; the amount is computed by the service.
+// With an IdempotencyKey, repeating the call returns the order created by the
+// first one, which also makes the call safe to retry.
+type NewOrder struct {
+	CustomerID     string
+	Status         string
+	Items          []Item
+	IdempotencyKey string
+}
+
+type ListOptions struct {
+	// IncludeDeleted also lists soft-deleted orders; it requires the admin role.
+	IncludeDeleted bool
+}
+
+// Options configure a client of any transport.
+type Options struct {
+	// Token is sent as a bearer token with every call.
+	Token string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// MaxRetries is how many more attempts a call that is safe to repeat gets
+	// after a network failure or an ErrUnavailable or ErrTimeout response.
+	// Zero disables retries. ListOrders, CreateOrder with an idempotency key,
+	// and UpdateOrderStatus and RestoreOrder without a version are retried;
+	// DeleteOrder is not. A retried versioned call would fail with
+	// ErrPrecondition whenever the lost attempt had been applied.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled before each
+	// further one. Defaults to 100ms.
+	RetryBackoff time.Duration
+}
+
+const defaultRetryBackoff = 100 * time.Millisecond
+
+func (o Options) httpClient() *http.Client {
+	if o.HTTPClient != nil {
+		return o.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// retry runs call until it succeeds, fails permanently or runs out of
+// attempts. Calls that are not safe to repeat run exactly once.
+func (o Options) retry(ctx context.Context, repeatable bool, call func() error) error {
+	backoff := o.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := call()
+		if err == nil || !repeatable || attempt >= o.MaxRetries || !transient(ctx, err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// transient reports whether err may go away on its own: the request did not
+// get through, or the service was unavailable or too slow.
+func transient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	return errors.Is(err, ErrUnavailable) || errors.Is(err, ErrTimeout)
+}
+
+// newRequest builds an authenticated request to the service; body, when not
+// nil, is sent as JSON.
+func (o Options) newRequest(ctx context.Context, method, target string, body []byte) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if o.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+o.Token)
+	}
+	return req, nil
+}
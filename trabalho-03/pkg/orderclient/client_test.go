@@ -0,0 +1,94 @@
+package orderclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first failures requests with 503 and then answers
+// with an empty JSON list; calls counts every request.
+func flakyServer(t *testing.T, failures int32, calls *atomic.Int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRetry_RepeatsSafeCallsOnTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	client := NewREST(flakyServer(t, 2, &calls).URL, Options{MaxRetries: 2, RetryBackoff: time.Millisecond})
+
+	if _, err := client.ListOrders(context.Background(), ListOptions{}); err != nil {
+		t.Fatalf("Expected the third attempt to succeed, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls.Load())
+	}
+}
+
+func TestRetry_GivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	client := NewREST(flakyServer(t, 5, &calls).URL, Options{MaxRetries: 1, RetryBackoff: time.Millisecond})
+
+	_, err := client.ListOrders(context.Background(), ListOptions{})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable, got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls.Load())
+	}
+}
+
+func TestRetry_SkipsCallsThatAreNotSafeToRepeat(t *testing.T) {
+	var calls atomic.Int32
+	client := NewREST(flakyServer(t, 5, &calls).URL, Options{MaxRetries: 3, RetryBackoff: time.Millisecond})
+
+	if err := client.DeleteOrder(context.Background(), 1, 0); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable, got %v", err)
+	}
+	if _, err := client.CreateOrder(context.Background(), NewOrder{CustomerID: "customer-1"}); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable, got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Expected one attempt per call, got %d", calls.Load())
+	}
+}
+
+func TestRetry_StopsWhenContextIsDone(t *testing.T) {
+	var calls atomic.Int32
+	client := NewREST(flakyServer(t, 5, &calls).URL, Options{MaxRetries: 3, RetryBackoff: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.ListOrders(ctx, ListOptions{}); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Expected the last failure to be returned, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the backoff to end with the context, took %v", elapsed)
+	}
+}
+
+func TestGRPCTimeout(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                       "1m",
+		1500 * time.Millisecond: "1500m",
+		48 * time.Hour:          "172800S",
+	}
+	for d, want := range tests {
+		if got := grpcTimeout(d); got != want {
+			t.Errorf("grpcTimeout(%v) = %s, want %s", d, got, want)
+		}
+	}
+}
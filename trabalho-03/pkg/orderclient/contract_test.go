@@ -0,0 +1,254 @@
+package orderclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"trabalho-03/graphql"
+	"trabalho-03/internal/auth"
+	"trabalho-03/internal/event"
+	grpcserver "trabalho-03/internal/grpc"
+	"trabalho-03/internal/handler"
+	"trabalho-03/internal/repository"
+	"trabalho-03/internal/server"
+	"trabalho-03/internal/usecase"
+	"trabalho-03/pkg/orderclient"
+
+	"github.com/gin-gonic/gin"
+)
+
+// transports builds, for each transport, a client factory backed by its own
+// in-process server with fresh in-memory storage. wrap, when not nil, wraps the
+// server's handler; options are the base options of every client.
+type transport func(t *testing.T, wrap func(http.Handler) http.Handler, options orderclient.Options) func(token string) orderclient.Client
+
+var transports = map[string]transport{
+	"rest": func(t *testing.T, wrap func(http.Handler) http.Handler, options orderclient.Options) func(string) orderclient.Client {
+		orderUseCase, _, authenticator := newUseCases()
+		srv := newServer(t, wrap, handler.NewRouter(handler.NewOrderHandler(orderUseCase), authenticator,
+			server.NewReadiness(time.Second), 10*time.Second, time.Minute))
+		return func(token string) orderclient.Client {
+			options.Token = token
+			return orderclient.NewREST(srv.URL, options)
+		}
+	},
+	"graphql": func(t *testing.T, wrap func(http.Handler) http.Handler, options orderclient.Options) func(string) orderclient.Client {
+		orderUseCase, customerUseCase, authenticator := newUseCases()
+		resolver := &graphql.Resolver{OrderUseCase: orderUseCase, CustomerUseCase: customerUseCase, EventBus: event.NewBus()}
+		srv := newServer(t, wrap, graphql.NewHandler(resolver, authenticator, graphql.HandlerOptions{
+			Timeout: 10 * time.Second, MaxComplexity: 1000, MaxDepth: 6, APQCacheSize: 10,
+		}))
+		return func(token string) orderclient.Client {
+			options.Token = token
+			return orderclient.NewGraphQL(srv.URL+"/query", options)
+		}
+	},
+	"grpc": func(t *testing.T, wrap func(http.Handler) http.Handler, options orderclient.Options) func(string) orderclient.Client {
+		orderUseCase, _, authenticator := newUseCases()
+		srv := newServer(t, wrap, grpcserver.NewGRPCServer(orderUseCase,
+			grpcserver.TimeoutInterceptor(10*time.Second), grpcserver.AuthInterceptor(authenticator)).Handler())
+		return func(token string) orderclient.Client {
+			options.Token = token
+			return orderclient.NewGRPC(srv.URL, options)
+		}
+	},
+}
+
+func newServer(t *testing.T, wrap func(http.Handler) http.Handler, h http.Handler) *httptest.Server {
+	if wrap != nil {
+		h = wrap(h)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+const testSecret = "secret"
+
+func newUseCases() (*usecase.OrderUseCase, *usecase.CustomerUseCase, *auth.Authenticator) {
+	gin.SetMode(gin.TestMode)
+	return usecase.NewOrderUseCase(repository.NewMemoryOrderRepository(), event.NewBus()),
+		usecase.NewCustomerUseCase(repository.NewMemoryCustomerRepository()),
+		auth.NewAuthenticator(testSecret)
+}
+
+func token(t *testing.T, customerID, role string) string {
+	t.Helper()
+	token, err := auth.NewAuthenticator(testSecret).Issue(customerID, role, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+	return token
+}
+
+func newOrder(customerID, idempotencyKey string) orderclient.NewOrder {
+	return orderclient.NewOrder{
+		CustomerID:     customerID,
+		Status:         "pending",
+		Items:          []orderclient.Item{{ProductID: "product-1", Quantity: 2, UnitPriceCents: 1050}},
+		IdempotencyKey: idempotencyKey,
+	}
+}
+
+func TestContract_OrderLifecycle(t *testing.T) {
+	for name, start := range transports {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			admin := start(t, nil, orderclient.Options{})(token(t, "", auth.RoleAdmin))
+
+			order, err := admin.CreateOrder(ctx, newOrder("customer-1", "key-1"))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if order.ID == 0 || order.AmountCents != 2100 || order.Version != 1 || len(order.Items) != 1 || order.CreatedAt.IsZero() {
+				t.Errorf("Unexpected created order %+v", order)
+			}
+			replayed, err := admin.CreateOrder(ctx, newOrder("customer-1", "key-1"))
+			if err != nil || replayed.ID != order.ID {
+				t.Errorf("Expected the retry to return order %d, got %+v (err %v)", order.ID, replayed, err)
+			}
+
+			updated, err := admin.UpdateOrderStatus(ctx, order.ID, "confirmed", order.Version)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if updated.Status != "confirmed" || updated.Version != 2 {
+				t.Errorf("Expected confirmed at version 2, got %s at version %d", updated.Status, updated.Version)
+			}
+			if _, err := admin.UpdateOrderStatus(ctx, order.ID, "shipped", order.Version); !errors.Is(err, orderclient.ErrPrecondition) {
+				t.Errorf("Expected ErrPrecondition for a stale version, got %v", err)
+			}
+
+			if err := admin.DeleteOrder(ctx, order.ID, updated.Version); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if orders, err := admin.ListOrders(ctx, orderclient.ListOptions{}); err != nil || len(orders) != 0 {
+				t.Errorf("Expected no live orders, got %d (err %v)", len(orders), err)
+			}
+			orders, err := admin.ListOrders(ctx, orderclient.ListOptions{IncludeDeleted: true})
+			if err != nil || len(orders) != 1 || orders[0].DeletedAt == nil {
+				t.Fatalf("Expected the deleted order to be listed, got %+v (err %v)", orders, err)
+			}
+
+			restored, err := admin.RestoreOrder(ctx, order.ID, orders[0].Version)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if restored.DeletedAt != nil || restored.Version != 4 {
+				t.Errorf("Expected a live order at version 4, got %+v", restored)
+			}
+		})
+	}
+}
+
+func TestContract_Errors(t *testing.T) {
+	for name, start := range transports {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			clients := start(t, nil, orderclient.Options{})
+			admin := clients(token(t, "", auth.RoleAdmin))
+			customer := clients(token(t, "customer-1", auth.RoleCustomer))
+
+			invalid := newOrder("customer-1", "")
+			invalid.Items = nil
+			_, err := admin.CreateOrder(ctx, invalid)
+			var clientErr *orderclient.Error
+			if !errors.As(err, &clientErr) || clientErr.Code != orderclient.CodeInvalid {
+				t.Fatalf("Expected ErrInvalid, got %v", err)
+			}
+			if len(clientErr.Fields) != 1 || clientErr.Fields[0].Field != "items" {
+				t.Errorf("Expected the items field to be reported, got %+v", clientErr.Fields)
+			}
+
+			if _, err := admin.UpdateOrderStatus(ctx, 42, "confirmed", 0); !errors.Is(err, orderclient.ErrNotFound) {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+			if err := admin.DeleteOrder(ctx, 42, 0); !errors.Is(err, orderclient.ErrNotFound) {
+				t.Errorf("Expected ErrNotFound deleting, got %v", err)
+			}
+			if _, err := clients("").ListOrders(ctx, orderclient.ListOptions{}); !errors.Is(err, orderclient.ErrUnauthenticated) {
+				t.Errorf("Expected ErrUnauthenticated, got %v", err)
+			}
+			if _, err := customer.CreateOrder(ctx, newOrder("customer-2", "")); !errors.Is(err, orderclient.ErrForbidden) {
+				t.Errorf("Expected ErrForbidden creating for another customer, got %v", err)
+			}
+			if _, err := customer.ListOrders(ctx, orderclient.ListOptions{IncludeDeleted: true}); !errors.Is(err, orderclient.ErrForbidden) {
+				t.Errorf("Expected ErrForbidden listing deleted orders, got %v", err)
+			}
+
+			if _, err := customer.CreateOrder(ctx, newOrder("customer-1", "key-1")); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if _, err := customer.CreateOrder(ctx, newOrder("customer-1", "key-1")); err != nil {
+				t.Errorf("Expected the same request to be replayed, got %v", err)
+			}
+			other := newOrder("customer-1", "key-1")
+			other.Status = "confirmed"
+			if _, err := customer.CreateOrder(ctx, other); !errors.Is(err, orderclient.ErrConflict) {
+				t.Errorf("Expected ErrConflict reusing the key, got %v", err)
+			}
+		})
+	}
+}
+
+// responseDropper serves requests normally but, once armed, aborts the
+// connection after the next request has been handled, as if the response had
+// been lost on the way back.
+type responseDropper struct {
+	armed    atomic.Bool
+	requests atomic.Int32
+}
+
+func (d *responseDropper) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d.requests.Add(1)
+		if !d.armed.CompareAndSwap(true, false) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(httptest.NewRecorder(), r)
+		panic(http.ErrAbortHandler)
+	})
+}
+
+func TestContract_VersionedCallsAreNotRetried(t *testing.T) {
+	for name, start := range transports {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			dropper := &responseDropper{}
+			admin := start(t, dropper.wrap, orderclient.Options{MaxRetries: 2, RetryBackoff: time.Millisecond})(token(t, "", auth.RoleAdmin))
+
+			order, err := admin.CreateOrder(ctx, newOrder("customer-1", ""))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// The update is applied but its response is lost: a retry would
+			// fail with ErrPrecondition, so the call must not be repeated.
+			dropper.armed.Store(true)
+			before := dropper.requests.Load()
+			_, err = admin.UpdateOrderStatus(ctx, order.ID, "confirmed", order.Version)
+			if err == nil || errors.Is(err, orderclient.ErrPrecondition) {
+				t.Errorf("Expected the lost response to be reported, got %v", err)
+			}
+			if requests := dropper.requests.Load() - before; requests != 1 {
+				t.Errorf("Expected a single attempt, got %d", requests)
+			}
+			orders, err := admin.ListOrders(ctx, orderclient.ListOptions{})
+			if err != nil || len(orders) != 1 || orders[0].Status != "confirmed" || orders[0].Version != 2 {
+				t.Fatalf("Expected the update to be applied, got %+v (err %v)", orders, err)
+			}
+
+			// Without a version the update is safe to repeat.
+			dropper.armed.Store(true)
+			updated, err := admin.UpdateOrderStatus(ctx, order.ID, "shipped", 0)
+			if err != nil || updated.Status != "shipped" {
+				t.Errorf("Expected the retry to succeed, got %+v (err %v)", updated, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package orderclient
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Code classifies a failure reported by the service. The values are the
+// codes the service uses in REST problem details and GraphQL errors.
+type Code string
+
+const (
+	CodeInvalid         Code = "BAD_USER_INPUT"
+	CodeNotFound        Code = "NOT_FOUND"
+	CodeConflict        Code = "CONFLICT"
+	CodePrecondition    Code = "PRECONDITION_FAILED"
+	CodeUnauthenticated Code = "UNAUTHENTICATED"
+	CodeForbidden       Code = "FORBIDDEN"
+	CodeTimeout         Code = "DEADLINE_EXCEEDED"
+	CodeCanceled        Code = "CANCELED"
+	CodeUnavailable     Code = "UNAVAILABLE"
+	CodeInternal        Code = "INTERNAL_SERVER_ERROR"
+)
+
+// Sentinels to test errors against with errors.Is; any *Error with the same
+// Code matches.
+var (
+	ErrInvalid         = &Error{Code: CodeInvalid}
+	ErrNotFound        = &Error{Code: CodeNotFound}
+	ErrConflict        = &Error{Code: CodeConflict}
+	ErrPrecondition    = &Error{Code: CodePrecondition}
+	ErrUnauthenticated = &Error{Code: CodeUnauthenticated}
+	ErrForbidden       = &Error{Code: CodeForbidden}
+	ErrTimeout         = &Error{Code: CodeTimeout}
+	ErrCanceled        = &Error{Code: CodeCanceled}
+	ErrUnavailable     = &Error{Code: CodeUnavailable}
+	ErrInternal        = &Error{Code: CodeInternal}
+)
+
+// FieldError describes an invalid input field of an ErrInvalid failure.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a failure reported by the service, whatever the transport.
+type Error struct {
+	Code    Code
+	Message string
+	// Fields lists the invalid fields when Code is CodeInvalid.
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return string(e.Code)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Is matches any *Error with the same Code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// statusCodes classifies HTTP statuses for responses without a usable body,
+// such as those of proxies in front of the service.
+var statusCodes = map[int]Code{
+	http.StatusBadRequest:          CodeInvalid,
+	http.StatusUnauthorized:        CodeUnauthenticated,
+	http.StatusForbidden:           CodeForbidden,
+	http.StatusNotFound:            CodeNotFound,
+	http.StatusConflict:            CodeConflict,
+	http.StatusPreconditionFailed:  CodePrecondition,
+	http.StatusTooManyRequests:     CodeUnavailable,
+	499:                            CodeCanceled,
+	http.StatusBadGateway:          CodeUnavailable,
+	http.StatusServiceUnavailable:  CodeUnavailable,
+	http.StatusGatewayTimeout:      CodeTimeout,
+	http.StatusInternalServerError: CodeInternal,
+}
+
+func statusError(status int) *Error {
+	code, ok := statusCodes[status]
+	if !ok {
+		code = CodeInternal
+	}
+	return &Error{Code: code, Message: http.StatusText(status)}
+}
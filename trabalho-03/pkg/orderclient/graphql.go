@@ -0,0 +1,253 @@
+package orderclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type graphQLClient struct {
+	endpoint string
+	options  Options
+}
+
+// NewGraphQL returns a Client of the GraphQL API at endpoint, e.g.
+// "http://localhost:8081/query".
+func NewGraphQL(endpoint string, options Options) Client {
+	return &graphQLClient{endpoint: endpoint, options: options}
+}
+
+const graphQLOrderFields = `id customerId items { productId quantity unitPriceCents } amountCents status version createdAt updatedAt deletedAt`
+
+type graphQLOrder struct {
+	ID          string `json:"id"`
+	CustomerID  string `json:"customerId"`
+	AmountCents int64  `json:"amountCents"`
+	Status      string `json:"status"`
+	Version     uint   `json:"version"`
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
+	DeletedAt   string `json:"deletedAt"`
+	Items       []struct {
+		ProductID      string `json:"productId"`
+		Quantity       int32  `json:"quantity"`
+		UnitPriceCents int64  `json:"unitPriceCents"`
+	} `json:"items"`
+}
+
+func (o *graphQLOrder) toOrder() (*Order, error) {
+	id, err := strconv.ParseUint(o.ID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	order := &Order{
+		ID:          uint(id),
+		CustomerID:  o.CustomerID,
+		AmountCents: o.AmountCents,
+		Status:      o.Status,
+		Version:     o.Version,
+		Items:       make([]Item, 0, len(o.Items)),
+	}
+	if order.CreatedAt, err = time.Parse(time.RFC3339, o.CreatedAt); err != nil {
+		return nil, err
+	}
+	if order.UpdatedAt, err = time.Parse(time.RFC3339, o.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if o.DeletedAt != "" {
+		deletedAt, err := time.Parse(time.RFC3339, o.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		order.DeletedAt = &deletedAt
+	}
+	for _, item := range o.Items {
+		order.Items = append(order.Items, Item{
+			ProductID:      item.ProductID,
+			Quantity:       item.Quantity,
+			UnitPriceCents: item.UnitPriceCents,
+		})
+	}
+	return order, nil
+}
+
+func (c *graphQLClient) CreateOrder(ctx context.Context, order NewOrder) (*Order, error) {
+	items := make([]map[string]any, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, map[string]any{
+			"productId":      item.ProductID,
+			"quantity":       item.Quantity,
+			"unitPriceCents": item.UnitPriceCents,
+		})
+	}
+	input := map[string]any{"customerId": order.CustomerID, "status": order.Status, "items": items}
+	if order.IdempotencyKey != "" {
+		input["idempotencyKey"] = order.IdempotencyKey
+	}
+
+	var data struct {
+		Order graphQLOrder `json:"createOrder"`
+	}
+	err := c.options.retry(ctx, order.IdempotencyKey != "", func() error {
+		return c.do(ctx, `mutation($input: CreateOrderInput!) { createOrder(input: $input) { `+graphQLOrderFields+` } }`,
+			map[string]any{"input": input}, &data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data.Order.toOrder()
+}
+
+func (c *graphQLClient) ListOrders(ctx context.Context, options ListOptions) ([]Order, error) {
+	var data struct {
+		Orders []graphQLOrder `json:"orders"`
+	}
+	err := c.options.retry(ctx, true, func() error {
+		return c.do(ctx, `query($includeDeleted: Boolean) { orders(includeDeleted: $includeDeleted) { `+graphQLOrderFields+` } }`,
+			map[string]any{"includeDeleted": options.IncludeDeleted}, &data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]Order, 0, len(data.Orders))
+	for i := range data.Orders {
+		order, err := data.Orders[i].toOrder()
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
+	}
+	return orders, nil
+}
+
+func (c *graphQLClient) UpdateOrderStatus(ctx context.Context, id uint, status string, version uint) (*Order, error) {
+	variables := targetVariables(id, version)
+	variables["status"] = status
+
+	var data struct {
+		Order graphQLOrder `json:"updateOrderStatus"`
+	}
+	err := c.options.retry(ctx, version == 0, func() error {
+		return c.do(ctx, `mutation($id: ID!, $status: String!, $version: Int) { updateOrderStatus(id: $id, status: $status, version: $version) { `+graphQLOrderFields+` } }`,
+			variables, &data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data.Order.toOrder()
+}
+
+func (c *graphQLClient) DeleteOrder(ctx context.Context, id uint, version uint) error {
+	var data struct {
+		Order struct {
+			ID string `json:"id"`
+		} `json:"deleteOrder"`
+	}
+	return c.do(ctx, `mutation($id: ID!, $version: Int) { deleteOrder(id: $id, version: $version) { id } }`,
+		targetVariables(id, version), &data)
+}
+
+func (c *graphQLClient) RestoreOrder(ctx context.Context, id uint, version uint) (*Order, error) {
+	var data struct {
+		Order graphQLOrder `json:"restoreOrder"`
+	}
+	err := c.options.retry(ctx, version == 0, func() error {
+		return c.do(ctx, `mutation($id: ID!, $version: Int) { restoreOrder(id: $id, version: $version) { `+graphQLOrderFields+` } }`,
+			targetVariables(id, version), &data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data.Order.toOrder()
+}
+
+// targetVariables holds the id and, when set, the version of the order a
+// mutation applies to.
+func targetVariables(id uint, version uint) map[string]any {
+	variables := map[string]any{"id": strconv.FormatUint(uint64(id), 10)}
+	if version != 0 {
+		variables["version"] = version
+	}
+	return variables
+}
+
+type graphQLError struct {
+	Message    string `json:"message"`
+	Extensions struct {
+		Code  Code   `json:"code"`
+		Field string `json:"field"`
+	} `json:"extensions"`
+}
+
+// do runs an operation and decodes its data into out. Errors in the response
+// fail the call even when some data was returned.
+func (c *graphQLClient) do(ctx context.Context, query string, variables map[string]any, out any) error {
+	payload, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := c.options.newRequest(ctx, http.MethodPost, c.endpoint, payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.options.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if json.Unmarshal(body, &result) != nil {
+		return statusError(resp.StatusCode)
+	}
+	if len(result.Errors) > 0 {
+		return graphQLFailure(result.Errors)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp.StatusCode)
+	}
+	return json.Unmarshal(result.Data, out)
+}
+
+// graphQLFailure folds the per-field errors of BAD_USER_INPUT failures into a
+// single Error; otherwise the first error wins. Errors without a code come
+// from the GraphQL layer itself, e.g. a malformed query, and are internal to
+// the client.
+func graphQLFailure(errs []graphQLError) error {
+	failure := &Error{Code: errs[0].Extensions.Code, Message: errs[0].Message}
+	if failure.Code == "" {
+		failure.Code = CodeInternal
+	}
+	if failure.Code != CodeInvalid {
+		return failure
+	}
+
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		if e.Extensions.Code != CodeInvalid {
+			continue
+		}
+		messages = append(messages, e.Message)
+		if e.Extensions.Field != "" {
+			failure.Fields = append(failure.Fields, FieldError{
+				Field:   e.Extensions.Field,
+				Message: strings.TrimPrefix(e.Message, e.Extensions.Field+": "),
+			})
+		}
+	}
+	failure.Message = strings.Join(messages, "; ")
+	return failure
+}
@@ -0,0 +1,162 @@
+package orderclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type grpcClient struct {
+	baseURL string
+	options Options
+}
+
+// NewGRPC returns a Client of the gRPC-style OrderService served over
+// HTTP/JSON at baseURL, e.g. "http://localhost:9090". Metadata travels in
+// HTTP headers and the context deadline is sent as grpc-timeout.
+func NewGRPC(baseURL string, options Options) Client {
+	return &grpcClient{baseURL: strings.TrimSuffix(baseURL, "/"), options: options}
+}
+
+type grpcOrderResponse struct {
+	Order *Order `json:"order"`
+}
+
+func (c *grpcClient) CreateOrder(ctx context.Context, order NewOrder) (*Order, error) {
+	req := map[string]any{"customer_id": order.CustomerID, "status": order.Status, "items": order.Items}
+	metadata := http.Header{}
+	if order.IdempotencyKey != "" {
+		metadata.Set("idempotency-key", order.IdempotencyKey)
+	}
+
+	var resp grpcOrderResponse
+	err := c.options.retry(ctx, order.IdempotencyKey != "", func() error {
+		return c.call(ctx, "CreateOrder", metadata, req, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Order, nil
+}
+
+func (c *grpcClient) ListOrders(ctx context.Context, options ListOptions) ([]Order, error) {
+	req := map[string]any{"include_deleted": options.IncludeDeleted}
+
+	var resp struct {
+		Orders []Order `json:"orders"`
+	}
+	err := c.options.retry(ctx, true, func() error {
+		return c.call(ctx, "ListOrders", nil, req, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Orders, nil
+}
+
+func (c *grpcClient) UpdateOrderStatus(ctx context.Context, id uint, status string, version uint) (*Order, error) {
+	req := map[string]any{"id": id, "status": status, "version": version}
+
+	var resp grpcOrderResponse
+	err := c.options.retry(ctx, version == 0, func() error {
+		return c.call(ctx, "UpdateOrderStatus", nil, req, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Order, nil
+}
+
+func (c *grpcClient) DeleteOrder(ctx context.Context, id uint, version uint) error {
+	req := map[string]any{"id": id, "version": version}
+	return c.call(ctx, "DeleteOrder", nil, req, &grpcOrderResponse{})
+}
+
+func (c *grpcClient) RestoreOrder(ctx context.Context, id uint, version uint) (*Order, error) {
+	req := map[string]any{"id": id, "version": version}
+
+	var resp grpcOrderResponse
+	err := c.options.retry(ctx, version == 0, func() error {
+		return c.call(ctx, "RestoreOrder", nil, req, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Order, nil
+}
+
+// call invokes /order.OrderService/<method>.
+func (c *grpcClient) call(ctx context.Context, method string, metadata http.Header, req, resp any) error {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := c.options.newRequest(ctx, http.MethodPost, c.baseURL+"/order.OrderService/"+method, payload)
+	if err != nil {
+		return err
+	}
+	for name, values := range metadata {
+		httpReq.Header[name] = values
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		httpReq.Header.Set("grpc-timeout", grpcTimeout(time.Until(deadline)))
+	}
+
+	httpResp, err := c.options.httpClient().Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return decodeStatus(httpResp)
+	}
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
+
+// grpcTimeout encodes d in milliseconds, or in seconds when it does not fit
+// in the eight digits the format allows.
+func grpcTimeout(d time.Duration) string {
+	if ms := d.Milliseconds(); ms < 1e8 {
+		return strconv.FormatInt(max(ms, 1), 10) + "m"
+	}
+	return strconv.FormatInt(min(int64(d/time.Second), 1e8-1), 10) + "S"
+}
+
+// grpcCodes maps the status code names sent by the service to Codes.
+var grpcCodes = map[string]Code{
+	"InvalidArgument":    CodeInvalid,
+	"OutOfRange":         CodeInvalid,
+	"NotFound":           CodeNotFound,
+	"AlreadyExists":      CodeConflict,
+	"Aborted":            CodePrecondition,
+	"FailedPrecondition": CodePrecondition,
+	"Unauthenticated":    CodeUnauthenticated,
+	"PermissionDenied":   CodeForbidden,
+	"DeadlineExceeded":   CodeTimeout,
+	"Canceled":           CodeCanceled,
+	"Unavailable":        CodeUnavailable,
+	"ResourceExhausted":  CodeUnavailable,
+}
+
+func decodeStatus(resp *http.Response) error {
+	var status struct {
+		Code    string       `json:"code"`
+		Message string       `json:"message"`
+		Details []FieldError `json:"details"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if json.Unmarshal(body, &status) != nil || status.Code == "" {
+		return statusError(resp.StatusCode)
+	}
+
+	code, ok := grpcCodes[status.Code]
+	if !ok {
+		code = CodeInternal
+	}
+	return &Error{Code: code, Message: status.Message, Fields: status.Details}
+}
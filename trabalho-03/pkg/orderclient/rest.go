@@ -0,0 +1,150 @@
+package orderclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type restClient struct {
+	baseURL string
+	options Options
+}
+
+// NewREST returns a Client of the REST API at baseURL, e.g.
+// "http://localhost:8080". Versions are sent in If-Match.
+func NewREST(baseURL string, options Options) Client {
+	return &restClient{baseURL: strings.TrimSuffix(baseURL, "/"), options: options}
+}
+
+type restOrderRequest struct {
+	CustomerID string `json:"customer_id"`
+	Status     string `json:"status"`
+	Items      []Item `json:"items"`
+}
+
+func (c *restClient) CreateOrder(ctx context.Context, order NewOrder) (*Order, error) {
+	body := restOrderRequest{CustomerID: order.CustomerID, Status: order.Status, Items: order.Items}
+	header := http.Header{}
+	if order.IdempotencyKey != "" {
+		header.Set("Idempotency-Key", order.IdempotencyKey)
+	}
+
+	created := &Order{}
+	err := c.options.retry(ctx, order.IdempotencyKey != "", func() error {
+		return c.do(ctx, http.MethodPost, "/order", header, body, created)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (c *restClient) ListOrders(ctx context.Context, options ListOptions) ([]Order, error) {
+	path := "/order"
+	if options.IncludeDeleted {
+		path += "?include_deleted=true"
+	}
+
+	var orders []Order
+	err := c.options.retry(ctx, true, func() error {
+		return c.do(ctx, http.MethodGet, path, nil, nil, &orders)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (c *restClient) UpdateOrderStatus(ctx context.Context, id uint, status string, version uint) (*Order, error) {
+	path := fmt.Sprintf("/order/%d/status", id)
+	body := map[string]string{"status": status}
+
+	order := &Order{}
+	err := c.options.retry(ctx, version == 0, func() error {
+		return c.do(ctx, http.MethodPatch, path, ifMatch(version), body, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (c *restClient) DeleteOrder(ctx context.Context, id uint, version uint) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/order/%d", id), ifMatch(version), nil, nil)
+}
+
+func (c *restClient) RestoreOrder(ctx context.Context, id uint, version uint) (*Order, error) {
+	path := fmt.Sprintf("/order/%d/restore", id)
+
+	order := &Order{}
+	err := c.options.retry(ctx, version == 0, func() error {
+		return c.do(ctx, http.MethodPost, path, ifMatch(version), nil, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// ifMatch carries version as the ETag the API returned for it.
+func ifMatch(version uint) http.Header {
+	header := http.Header{}
+	if version != 0 {
+		header.Set("If-Match", `"`+strconv.FormatUint(uint64(version), 10)+`"`)
+	}
+	return header
+}
+
+// do sends body, when not nil, as JSON and decodes a successful response into
+// out, when not nil.
+func (c *restClient) do(ctx context.Context, method, path string, header http.Header, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := c.options.newRequest(ctx, method, c.baseURL+path, payload)
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := c.options.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeProblem(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// problem holds the fields of the API's problem details used by the client.
+type problem struct {
+	Detail string       `json:"detail"`
+	Code   Code         `json:"code"`
+	Errors []FieldError `json:"errors"`
+}
+
+func decodeProblem(resp *http.Response) error {
+	var p problem
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if json.Unmarshal(body, &p) != nil || p.Code == "" {
+		return statusError(resp.StatusCode)
+	}
+	return &Error{Code: p.Code, Message: p.Detail, Fields: p.Errors}
+}
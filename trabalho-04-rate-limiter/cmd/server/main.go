@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 
+	"rate-limiter/internal/admin"
 	"rate-limiter/internal/limiter"
 	"rate-limiter/internal/middleware"
 	"rate-limiter/internal/storage"
@@ -20,7 +21,10 @@ func main() {
 	}
 
 	// Load configuration
-	config := limiter.LoadConfig()
+	config, err := limiter.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load rate limiter configuration: %v", err)
+	}
 
 	// Initialize Redis storage
 	redisHost := getEnv("REDIS_HOST", "localhost")
@@ -68,7 +72,7 @@ func main() {
 
 	// Rate limit status endpoint
 	router.GET("/api/rate-limit/status", func(c *gin.Context) {
-		ip := c.ClientIP()
+		ip := middleware.ClientIP(c, config)
 		apiKey := c.GetHeader("API_KEY")
 		
 		remaining, err := rateLimiter.GetRemainingRequests(c.Request.Context(), ip, apiKey)
@@ -86,7 +90,7 @@ func main() {
 
 	// Reset rate limit endpoint (for testing)
 	router.POST("/api/rate-limit/reset", func(c *gin.Context) {
-		ip := c.ClientIP()
+		ip := middleware.ClientIP(c, config)
 		apiKey := c.GetHeader("API_KEY")
 		
 		if err := rateLimiter.Reset(c.Request.Context(), ip, apiKey); err != nil {
@@ -99,14 +103,28 @@ func main() {
 		})
 	})
 
+	// Allow/deny list management
+	if adminToken := getEnv("RATE_LIMIT_ADMIN_TOKEN", ""); adminToken != "" {
+		admin.RegisterAccessRoutes(router.Group("/admin"), rateLimiter, adminToken)
+	} else {
+		log.Printf("RATE_LIMIT_ADMIN_TOKEN not set; allow/deny list endpoints disabled")
+	}
+
 	// Start server
 	port := getEnv("SERVER_PORT", "8080")
 	log.Printf("Starting server on port %s", port)
 	log.Printf("Rate limiter configuration:")
 	log.Printf("  IP requests per second: %d", config.IPRequestsPerSecond)
 	log.Printf("  IP block duration: %d minutes", config.IPBlockDurationMinutes)
+	log.Printf("  IP algorithm: %s", config.IPAlgorithm)
 	log.Printf("  Token requests per second: %d", config.TokenRequestsPerSecond)
 	log.Printf("  Token block duration: %d minutes", config.TokenBlockDurationMinutes)
+	log.Printf("  Token algorithm: %s", config.TokenAlgorithm)
+	log.Printf("  Trusted proxies: %v (client IP header: %s)", config.TrustedProxies, config.ClientIPHeader)
+	log.Printf("  IPv6 prefix length: /%d", config.IPv6PrefixLength)
+	if config.PolicyFile != "" {
+		log.Printf("  Token tiers: %d from %s", len(config.TokenTiers), config.PolicyFile)
+	}
 	
 	if err := router.Run(":" + port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
@@ -22,7 +22,10 @@ func main() {
 	defer storage.Close()
 
 	// Load configuration
-	config := limiter.LoadConfig()
+	config, err := limiter.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load rate limiter configuration: %v", err)
+	}
 
 	// Initialize rate limiter
 	rateLimiter := limiter.NewRateLimiter(storage, config)
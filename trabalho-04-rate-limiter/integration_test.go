@@ -5,10 +5,15 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
 	"testing"
 	"time"
 
+	"rate-limiter/internal/admin"
 	"rate-limiter/internal/limiter"
+	"rate-limiter/internal/middleware"
 	"rate-limiter/internal/storage"
 
 	"github.com/gin-gonic/gin"
@@ -39,7 +44,7 @@ func TestRateLimiterIntegration(t *testing.T) {
 		ip := c.ClientIP()
 		apiKey := c.GetHeader("API_KEY")
 
-		allowed, err := rateLimiter.CheckRequest(ctx, ip, apiKey)
+		decision, err := rateLimiter.CheckRequest(ctx, ip, apiKey)
 		if err != nil {
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error": "you have reached the maximum number of requests or actions allowed within a certain time frame",
@@ -48,7 +53,7 @@ func TestRateLimiterIntegration(t *testing.T) {
 			return
 		}
 
-		if !allowed {
+		if !decision.Allowed {
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error": "you have reached the maximum number of requests or actions allowed within a certain time frame",
 			})
@@ -160,6 +165,202 @@ func TestRateLimiterIntegration(t *testing.T) {
 	})
 }
 
+func TestRateLimitMiddlewareRoutePolicies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	config := &limiter.Config{
+		IPRequestsPerSecond: 5,
+		RoutePolicies: []limiter.RoutePolicy{
+			{Path: "/health", Exempt: true},
+			{Method: "POST", Path: "/api/data", Policy: limiter.Policy{Requests: 1, Window: time.Second}},
+		},
+	}
+	rateLimiter := limiter.NewRateLimiter(storage.NewMemoryStorage(), config)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	router := gin.New()
+	router.GET("/health", middleware.RateLimitMiddleware(rateLimiter), ok)
+	router.POST("/api/data", middleware.RateLimitMiddleware(rateLimiter), ok)
+	router.GET("/api/data", middleware.RateLimitMiddleware(rateLimiter), ok)
+	admin := router.Group("/admin", middleware.RateLimitMiddleware(rateLimiter, middleware.WithRoutePolicy(limiter.RoutePolicy{
+		Name:   "admin",
+		Policy: limiter.Policy{Requests: 1, Window: time.Second},
+	})))
+	admin.GET("/users", ok)
+	admin.GET("/settings", ok)
+
+	request := func(method, path, ip string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = ip + ":12345"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("Stricter Route", func(t *testing.T) {
+		assert.Equal(t, 200, request("POST", "/api/data", "10.0.0.1"))
+		assert.Equal(t, 429, request("POST", "/api/data", "10.0.0.1"))
+		assert.Equal(t, 200, request("GET", "/api/data", "10.0.0.1"), "GET should only use the IP limit")
+	})
+
+	t.Run("Exempt Route", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, 200, request("GET", "/health", "10.0.0.2"), "Request %d should be exempt", i+1)
+		}
+	})
+
+	t.Run("Group Option", func(t *testing.T) {
+		assert.Equal(t, 200, request("GET", "/admin/users", "10.0.0.3"))
+		assert.Equal(t, 429, request("GET", "/admin/settings", "10.0.0.3"))
+		assert.Equal(t, 200, request("GET", "/admin/users", "10.0.0.4"))
+	})
+}
+
+func TestRateLimitMiddlewareHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	config := &limiter.Config{
+		IPRequestsPerSecond:    2,
+		IPBlockDurationMinutes: 1,
+		RoutePolicies:          []limiter.RoutePolicy{{Path: "/health", Exempt: true}},
+	}
+	rateLimiter := limiter.NewRateLimiter(storage.NewMemoryStorage(), config)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	router := gin.New()
+	router.Use(middleware.RateLimitMiddleware(rateLimiter))
+	router.GET("/", ok)
+	router.GET("/health", ok)
+
+	request := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = "10.0.1.1:12345"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("/")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Reset"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	request("/")
+	w = request("/")
+	assert.Equal(t, 429, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+
+	w = request("/health")
+	assert.Equal(t, 200, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"), "Exempt routes should not get rate limit headers")
+}
+
+func TestRateLimitMiddlewareTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	config := &limiter.Config{
+		IPRequestsPerSecond: 1,
+		TrustedProxies:      []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")},
+		IPv6PrefixLength:    64,
+	}
+	rateLimiter := limiter.NewRateLimiter(storage.NewMemoryStorage(), config)
+
+	router := gin.New()
+	router.Use(middleware.RateLimitMiddleware(rateLimiter))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(remoteAddr, xff string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		if xff != "" {
+			req.Header.Set("X-Forwarded-For", xff)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("Spoofed Header", func(t *testing.T) {
+		assert.Equal(t, 200, request("203.0.113.1:12345", "198.51.100.1"))
+		assert.Equal(t, 429, request("203.0.113.1:12345", "198.51.100.2"), "Headers from untrusted clients should be ignored")
+	})
+
+	t.Run("Trusted Proxy", func(t *testing.T) {
+		assert.Equal(t, 200, request("10.1.0.1:12345", "198.51.100.3"))
+		assert.Equal(t, 200, request("10.1.0.1:12345", "198.51.100.4"))
+		assert.Equal(t, 429, request("10.1.0.2:12345", "198.51.100.4"))
+		assert.Equal(t, 200, request("10.1.0.1:12345", "198.51.100.4, 198.51.100.5"), "The client appended by the proxy should win")
+	})
+
+	t.Run("IPv6 Network", func(t *testing.T) {
+		assert.Equal(t, 200, request("[2001:db8:1:2::1]:12345", ""))
+		assert.Equal(t, 429, request("[2001:db8:1:2::2]:12345", ""), "Addresses in the same /64 should share a limit")
+		assert.Equal(t, 200, request("[2001:db8:1:3::1]:12345", ""))
+	})
+}
+
+func TestAccessListEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	config := &limiter.Config{IPRequestsPerSecond: 1, IPBlockDurationMinutes: 1}
+	rateLimiter := limiter.NewRateLimiter(storage.NewMemoryStorage(), config)
+
+	router := gin.New()
+	admin.RegisterAccessRoutes(router.Group("/admin"), rateLimiter, "secret")
+	api := router.Group("/api", middleware.RateLimitMiddleware(rateLimiter))
+	api.GET("/data", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(method, path, ip, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.RemoteAddr = ip + ":12345"
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Admin-Token", "secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Admin Token", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/admin/access", nil)
+		req.Header.Set("X-Admin-Token", "guess")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, 401, w.Code)
+	})
+
+	t.Run("Manage Lists", func(t *testing.T) {
+		w := request("POST", "/admin/access/allow", "127.0.0.1", `{"rule": "10.1.0.0/16"}`)
+		assert.Equal(t, 201, w.Code)
+		assert.Contains(t, w.Body.String(), `"rule":"ip:10.1.0.0/16"`)
+		assert.Equal(t, 201, request("POST", "/admin/access/deny", "127.0.0.1", `{"rule": "203.0.113.5"}`).Code)
+		assert.Equal(t, 400, request("POST", "/admin/access/deny", "127.0.0.1", `{"rule": "nonsense"}`).Code)
+		assert.Equal(t, 404, request("POST", "/admin/access/maybe", "127.0.0.1", `{"rule": "10.0.0.1"}`).Code)
+
+		w = request("GET", "/admin/access", "127.0.0.1", "")
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, `{"allow": ["ip:10.1.0.0/16"], "deny": ["ip:203.0.113.5/32"]}`, w.Body.String())
+	})
+
+	t.Run("Lists Apply", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, 200, request("GET", "/api/data", "10.1.2.3", "").Code, "Allowlisted request %d should pass", i+1)
+		}
+		w := request("GET", "/api/data", "203.0.113.5", "")
+		assert.Equal(t, 403, w.Code)
+		assert.Empty(t, w.Header().Get("Retry-After"))
+	})
+
+	t.Run("Remove Rule", func(t *testing.T) {
+		assert.Equal(t, 204, request("DELETE", "/admin/access/deny?rule=203.0.113.5", "127.0.0.1", "").Code)
+		assert.Equal(t, 200, request("GET", "/api/data", "203.0.113.5", "").Code)
+		assert.Equal(t, 429, request("GET", "/api/data", "203.0.113.5", "").Code)
+	})
+}
+
 // mockResponseWriter implements http.ResponseWriter for testing
 type mockResponseWriter struct {
 	statusCode int
@@ -213,8 +414,8 @@ func TestRateLimiterLoadTest(t *testing.T) {
 				ip := fmt.Sprintf("192.168.1.%d", goroutineID%10)
 				token := fmt.Sprintf("token-%d", goroutineID%5)
 
-				allowed, err := rateLimiter.CheckRequest(ctx, ip, token)
-				results <- (allowed && err == nil)
+				decision, err := rateLimiter.CheckRequest(ctx, ip, token)
+				results <- (err == nil && decision.Allowed)
 			}
 		}(i)
 	}
@@ -0,0 +1,106 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+
+	"rate-limiter/internal/limiter"
+	"rate-limiter/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAccessRoutes adds the endpoints managing the allow and deny lists
+// to router, for requests carrying token in the X-Admin-Token header:
+//
+//	GET    /access               both lists
+//	POST   /access/:list         {"rule": "10.0.0.0/8"} adds a rule
+//	DELETE /access/:list?rule=…  removes a rule
+//
+// where :list is allow or deny and a rule is an IP, a CIDR or
+// token:<token>. The lists live in the limiter's storage, so changes reach
+// every instance sharing it.
+func RegisterAccessRoutes(router gin.IRouter, rateLimiter *limiter.RateLimiter, token string) {
+	group := router.Group("/access", requireToken(token))
+
+	group.GET("", func(c *gin.Context) {
+		lists := gin.H{}
+		for _, list := range []storage.AccessList{storage.AllowList, storage.DenyList} {
+			rules, err := rateLimiter.AccessRules(c.Request.Context(), list)
+			if err != nil {
+				log.Printf("Failed to list access rules: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list access rules"})
+				return
+			}
+			lists[string(list)] = rules
+		}
+		c.JSON(http.StatusOK, lists)
+	})
+
+	group.POST("/:list", func(c *gin.Context) {
+		list, ok := accessList(c)
+		if !ok {
+			return
+		}
+		var body struct {
+			Rule string `json:"rule" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "body must be {\"rule\": \"<ip, cidr or token:<token>>\"}"})
+			return
+		}
+		if _, err := limiter.AccessRule(body.Rule); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		rule, err := rateLimiter.AddAccessRule(c.Request.Context(), list, body.Rule)
+		if err != nil {
+			log.Printf("Failed to add access rule: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add access rule"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"list": list, "rule": rule})
+	})
+
+	group.DELETE("/:list", func(c *gin.Context) {
+		list, ok := accessList(c)
+		if !ok {
+			return
+		}
+		if _, err := limiter.AccessRule(c.Query("rule")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := rateLimiter.RemoveAccessRule(c.Request.Context(), list, c.Query("rule")); err != nil {
+			log.Printf("Failed to remove access rule: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove access rule"})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// requireToken rejects requests whose X-Admin-Token header is not token.
+func requireToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given := c.GetHeader("X-Admin-Token")
+		if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid admin token"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func accessList(c *gin.Context) (storage.AccessList, bool) {
+	switch list := storage.AccessList(c.Param("list")); list {
+	case storage.AllowList, storage.DenyList:
+		return list, true
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "unknown access list, want allow or deny"})
+	return "", false
+}
@@ -0,0 +1,98 @@
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"rate-limiter/internal/storage"
+)
+
+// AccessRule parses rule, an IP address, a CIDR or "token:<token>", into the
+// entry the allow and deny lists hold: "ip:<cidr>", with a bare address
+// standing for itself alone, or "token:<token>".
+func AccessRule(rule string) (string, error) {
+	rule = strings.TrimSpace(rule)
+	if token, ok := strings.CutPrefix(rule, "token:"); ok {
+		if token == "" {
+			return "", fmt.Errorf("empty token in access rule %q", rule)
+		}
+		return rule, nil
+	}
+
+	prefix, err := parseNetwork(strings.TrimPrefix(rule, "ip:"))
+	if err != nil {
+		return "", fmt.Errorf("invalid access rule %q: want an IP, a CIDR or token:<token>", rule)
+	}
+	return fmt.Sprintf("ip:%s", prefix), nil
+}
+
+// AddAccessRule puts rule in list, for every instance sharing the storage.
+func (rl *RateLimiter) AddAccessRule(ctx context.Context, list storage.AccessList, rule string) (string, error) {
+	entry, err := AccessRule(rule)
+	if err != nil {
+		return "", err
+	}
+	if err := rl.storage.AddAccess(ctx, list, entry); err != nil {
+		return "", fmt.Errorf("failed to add access rule: %w", err)
+	}
+	return entry, nil
+}
+
+// RemoveAccessRule takes rule out of list.
+func (rl *RateLimiter) RemoveAccessRule(ctx context.Context, list storage.AccessList, rule string) error {
+	entry, err := AccessRule(rule)
+	if err != nil {
+		return err
+	}
+	if err := rl.storage.RemoveAccess(ctx, list, entry); err != nil {
+		return fmt.Errorf("failed to remove access rule: %w", err)
+	}
+	return nil
+}
+
+// AccessRules lists the entries in list.
+func (rl *RateLimiter) AccessRules(ctx context.Context, list storage.AccessList) ([]string, error) {
+	return rl.storage.AccessEntries(ctx, list)
+}
+
+// accessEntries are the list entries matching a client: every network
+// containing ip, from the address itself down to /0, and its token. An ip that
+// is already a network, such as an aggregated IPv6 /64, only matches rules
+// as wide or wider.
+func accessEntries(ip, token string) []string {
+	var entries []string
+	if prefix, err := parseNetwork(ip); err == nil {
+		for bits := prefix.Bits(); bits >= 0; bits-- {
+			network, _ := prefix.Addr().Prefix(bits)
+			entries = append(entries, fmt.Sprintf("ip:%s", network))
+		}
+	}
+	if token != "" {
+		entries = append(entries, fmt.Sprintf("token:%s", token))
+	}
+	return entries
+}
+
+// parseNetwork parses a CIDR or an address, the latter as a network of its
+// own, with IPv4-mapped IPv6 addresses as IPv4.
+func parseNetwork(value string) (netip.Prefix, error) {
+	if !strings.Contains(value, "/") {
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		addr = addr.Unmap().WithZone("")
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked(), nil
+}
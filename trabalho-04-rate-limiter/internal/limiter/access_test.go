@@ -0,0 +1,136 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+
+	"rate-limiter/internal/storage"
+)
+
+func TestAccessRule(t *testing.T) {
+	tests := map[string]string{
+		"10.0.0.1":               "ip:10.0.0.1/32",
+		" 10.1.2.3/8 ":           "ip:10.0.0.0/8",
+		"ip:192.168.0.0/16":      "ip:192.168.0.0/16",
+		"2001:db8::1/64":         "ip:2001:db8::/64",
+		"::ffff:203.0.113.0/120": "ip:203.0.113.0/24",
+		"token:abc123":           "token:abc123",
+	}
+	for rule, want := range tests {
+		got, err := AccessRule(rule)
+		if err != nil {
+			t.Errorf("%q: expected no error, got %v", rule, err)
+		} else if got != want {
+			t.Errorf("%q: expected %s, got %s", rule, want, got)
+		}
+	}
+
+	for _, rule := range []string{"", "token:", "10.0.0.0/33", "abc123", "ip:"} {
+		if _, err := AccessRule(rule); err == nil {
+			t.Errorf("%q: expected an error", rule)
+		}
+	}
+}
+
+func TestAccessEntries(t *testing.T) {
+	entries := accessEntries("10.1.2.3", "abc123")
+	if len(entries) != 34 || entries[0] != "ip:10.1.2.3/32" || entries[24] != "ip:10.0.0.0/8" || entries[32] != "ip:0.0.0.0/0" || entries[33] != "token:abc123" {
+		t.Errorf("Expected every network of the IP and the token, got %v", entries)
+	}
+
+	entries = accessEntries("2001:db8:1:2::/64", "")
+	if len(entries) != 65 || entries[0] != "ip:2001:db8:1:2::/64" || entries[16] != "ip:2001:db8:1::/48" {
+		t.Errorf("Expected the networks containing the /64, got %v", entries)
+	}
+
+	if entries := accessEntries("not-an-ip", ""); len(entries) != 0 {
+		t.Errorf("Expected no entries, got %v", entries)
+	}
+}
+
+func TestRateLimiter_Check_AccessLists(t *testing.T) {
+	limiter := NewRateLimiter(storage.NewMemoryStorage(), &Config{
+		IPRequestsPerSecond:    1,
+		IPBlockDurationMinutes: 1,
+		TokenRequestsPerSecond: 1,
+	})
+	ctx := context.Background()
+
+	if _, err := limiter.AddAccessRule(ctx, storage.AllowList, "10.0.0.0/8"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := limiter.AddAccessRule(ctx, storage.DenyList, "203.0.113.0/24"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := limiter.AddAccessRule(ctx, storage.DenyList, "token:abuser"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := limiter.AddAccessRule(ctx, storage.DenyList, "nonsense"); err == nil {
+		t.Error("Expected an invalid rule to fail")
+	}
+
+	for i := 0; i < 5; i++ {
+		decision, err := limiter.CheckRequest(ctx, "10.9.8.7", "")
+		if err != nil || !decision.Allowed || decision.Policy != "allowlist" {
+			t.Fatalf("Expected allowlisted request %d to be allowed, got %+v, %v", i+1, decision, err)
+		}
+	}
+
+	decision, _ := limiter.CheckRequest(ctx, "203.0.113.9", "")
+	if decision.Allowed || !decision.Denied() {
+		t.Errorf("Expected the denied range to be rejected, got %+v", *decision)
+	}
+	decision, _ = limiter.CheckRequest(ctx, "10.9.8.7", "abuser")
+	if decision.Allowed || !decision.Denied() {
+		t.Errorf("Expected the denied token to be rejected even from an allowed IP, got %+v", *decision)
+	}
+
+	decision, _ = limiter.CheckRequest(ctx, "192.0.2.1", "")
+	if !decision.Allowed || decision.Denied() || decision.Policy != "ip" {
+		t.Errorf("Expected an unlisted client to use its own limit, got %+v", *decision)
+	}
+
+	if err := limiter.RemoveAccessRule(ctx, storage.AllowList, "ip:10.0.0.0/8"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	rules, err := limiter.AccessRules(ctx, storage.DenyList)
+	if err != nil || len(rules) != 2 || rules[0] != "ip:203.0.113.0/24" || rules[1] != "token:abuser" {
+		t.Errorf("Expected both deny rules, got %v, %v", rules, err)
+	}
+	limiter.CheckRequest(ctx, "10.9.8.7", "")
+	if decision, _ := limiter.CheckRequest(ctx, "10.9.8.7", ""); decision.Allowed {
+		t.Error("Expected the IP limit to apply once the rule is removed")
+	}
+}
+
+func TestRateLimiter_Check_DenyListOnExemptRoute(t *testing.T) {
+	limiter := NewRateLimiter(storage.NewMemoryStorage(), &Config{
+		IPRequestsPerSecond:    1,
+		TokenRequestsPerSecond: 1,
+		RoutePolicies:          []RoutePolicy{{Path: "/health", Exempt: true}},
+	})
+	ctx := context.Background()
+	limiter.AddAccessRule(ctx, storage.DenyList, "203.0.113.0/24")
+	limiter.AddAccessRule(ctx, storage.DenyList, "token:abuser")
+
+	health := Request{IP: "203.0.113.9", Method: "GET", Path: "/health"}
+	decision, err := limiter.Check(ctx, health)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if decision.Allowed || !decision.Denied() {
+		t.Errorf("Expected the denied range to be rejected on an exempt route, got %+v", *decision)
+	}
+
+	health.IP, health.Token = "192.0.2.1", "abuser"
+	if decision, _ := limiter.Check(ctx, health); decision.Allowed || !decision.Denied() {
+		t.Errorf("Expected the denied token to be rejected on an exempt route, got %+v", *decision)
+	}
+
+	health.Token = ""
+	for i := 0; i < 3; i++ {
+		if decision, _ := limiter.Check(ctx, health); !decision.Allowed || decision.Policy != "exempt" {
+			t.Fatalf("Expected an unlisted client to stay exempt, got %+v", *decision)
+		}
+	}
+}
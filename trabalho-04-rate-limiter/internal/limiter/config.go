@@ -1,27 +1,108 @@
 package limiter
 
 import (
+	"fmt"
+	"net/netip"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"rate-limiter/internal/storage"
 )
 
 type Config struct {
-	IPRequestsPerSecond    int
-	IPBlockDurationMinutes int
+	IPRequestsPerSecond       int
+	IPBlockDurationMinutes    int
+	IPAlgorithm               storage.Algorithm
 	TokenRequestsPerSecond    int
 	TokenBlockDurationMinutes int
+	TokenAlgorithm            storage.Algorithm
+	// PolicyFile is where TokenTiers and RoutePolicies were loaded from, if
+	// anywhere.
+	PolicyFile    string
+	TokenTiers    []TokenTier
+	RoutePolicies []RoutePolicy
+	// TrustedProxies are the proxies whose ClientIPHeader is believed; a
+	// request from any other address is limited by that address.
+	TrustedProxies []netip.Prefix
+	// ClientIPHeader is the one forwarding header the trusted proxies set,
+	// ClientIPHeaderXFF when empty. The others are ignored, since proxies
+	// pass headers they do not set through from the client.
+	ClientIPHeader string
+	// IPv6PrefixLength groups IPv6 clients into networks of this size, so
+	// one client cannot dodge its limit by hopping between the addresses of
+	// its own network. Zero or 128 limits each address on its own.
+	IPv6PrefixLength int
 }
 
-func LoadConfig() *Config {
+// Forwarding headers ClientIPHeader can name.
+const (
+	ClientIPHeaderXFF       = "xff"
+	ClientIPHeaderForwarded = "forwarded"
+	ClientIPHeaderXRealIP   = "x-real-ip"
+)
+
+func LoadConfig() (*Config, error) {
+	algorithm, err := getEnvAlgorithm("RATE_LIMIT_ALGORITHM", storage.FixedWindow)
+	if err != nil {
+		return nil, err
+	}
+	ipAlgorithm, err := getEnvAlgorithm("RATE_LIMIT_IP_ALGORITHM", algorithm)
+	if err != nil {
+		return nil, err
+	}
+	tokenAlgorithm, err := getEnvAlgorithm("RATE_LIMIT_TOKEN_ALGORITHM", algorithm)
+	if err != nil {
+		return nil, err
+	}
+
 	config := &Config{
-		IPRequestsPerSecond:        getEnvInt("RATE_LIMIT_IP_REQUESTS_PER_SECOND", 5),
-		IPBlockDurationMinutes:     getEnvInt("RATE_LIMIT_IP_BLOCK_DURATION_MINUTES", 5),
-		TokenRequestsPerSecond:     getEnvInt("RATE_LIMIT_TOKEN_REQUESTS_PER_SECOND", 10),
-		TokenBlockDurationMinutes:  getEnvInt("RATE_LIMIT_TOKEN_BLOCK_DURATION_MINUTES", 5),
+		IPRequestsPerSecond:       getEnvInt("RATE_LIMIT_IP_REQUESTS_PER_SECOND", 5),
+		IPBlockDurationMinutes:    getEnvInt("RATE_LIMIT_IP_BLOCK_DURATION_MINUTES", 5),
+		IPAlgorithm:               ipAlgorithm,
+		TokenRequestsPerSecond:    getEnvInt("RATE_LIMIT_TOKEN_REQUESTS_PER_SECOND", 10),
+		TokenBlockDurationMinutes: getEnvInt("RATE_LIMIT_TOKEN_BLOCK_DURATION_MINUTES", 5),
+		TokenAlgorithm:            tokenAlgorithm,
+		PolicyFile:                os.Getenv("RATE_LIMIT_POLICY_FILE"),
+		IPv6PrefixLength:          getEnvInt("RATE_LIMIT_IPV6_PREFIX", 64),
+		ClientIPHeader:            strings.ToLower(getEnv("RATE_LIMIT_CLIENT_IP_HEADER", ClientIPHeaderXFF)),
+	}
+
+	switch config.ClientIPHeader {
+	case ClientIPHeaderXFF, ClientIPHeaderForwarded, ClientIPHeaderXRealIP:
+	default:
+		return nil, fmt.Errorf("RATE_LIMIT_CLIENT_IP_HEADER must be %s, %s or %s, got %q",
+			ClientIPHeaderXFF, ClientIPHeaderForwarded, ClientIPHeaderXRealIP, config.ClientIPHeader)
+	}
+
+	if config.IPv6PrefixLength < 0 || config.IPv6PrefixLength > 128 {
+		return nil, fmt.Errorf("RATE_LIMIT_IPV6_PREFIX must be between 0 and 128, got %d", config.IPv6PrefixLength)
+	}
+
+	trustedProxies, err := parsePrefixes(os.Getenv("RATE_LIMIT_TRUSTED_PROXIES"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_TRUSTED_PROXIES: %w", err)
+	}
+	config.TrustedProxies = trustedProxies
+
+	if config.PolicyFile != "" {
+		policies, err := loadPolicyFile(config.PolicyFile, config.TokenPolicy(), Policy{Algorithm: algorithm})
+		if err != nil {
+			return nil, err
+		}
+		config.TokenTiers = policies.tiers
+		config.RoutePolicies = policies.routes
+	}
+
+	return config, nil
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
 	}
-	
-	return config
+	return defaultValue
 }
 
 func getEnvInt(key string, defaultValue int) int {
@@ -33,6 +114,38 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAlgorithm fails on an unknown algorithm rather than falling back to
+// defaultValue, so a typo cannot silently switch algorithms.
+func getEnvAlgorithm(key string, defaultValue storage.Algorithm) (storage.Algorithm, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	algorithm, err := storage.ParseAlgorithm(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return algorithm, nil
+}
+
+// parsePrefixes parses a comma-separated list of CIDRs, where a bare address
+// stands for itself alone.
+func parsePrefixes(value string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		prefix, err := parseNetwork(field)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
 func (c *Config) GetIPBlockDuration() time.Duration {
 	return time.Duration(c.IPBlockDurationMinutes) * time.Minute
 }
@@ -40,3 +153,38 @@ func (c *Config) GetIPBlockDuration() time.Duration {
 func (c *Config) GetTokenBlockDuration() time.Duration {
 	return time.Duration(c.TokenBlockDurationMinutes) * time.Minute
 }
+
+// IPPolicy is the limit for requests without a token.
+func (c *Config) IPPolicy() Policy {
+	return Policy{
+		Algorithm:     c.IPAlgorithm,
+		Requests:      c.IPRequestsPerSecond,
+		Window:        time.Second,
+		BlockDuration: c.GetIPBlockDuration(),
+	}
+}
+
+// TokenPolicy is the limit for requests with an API_KEY token that has no
+// tier of its own.
+func (c *Config) TokenPolicy() Policy {
+	return Policy{
+		Algorithm:     c.TokenAlgorithm,
+		Requests:      c.TokenRequestsPerSecond,
+		Window:        time.Second,
+		BlockDuration: c.GetTokenBlockDuration(),
+	}
+}
+
+// TokenPolicyFor is the limit for requests with token: the policy of its
+// tier, or TokenPolicy when it has none.
+func (c *Config) TokenPolicyFor(token string) Policy {
+	if tier, ok := tokenTier(c.TokenTiers, token); ok {
+		return tier.Policy
+	}
+	return c.TokenPolicy()
+}
+
+// RoutePolicyFor finds the first route policy matching method and path.
+func (c *Config) RoutePolicyFor(method, path string) (RoutePolicy, bool) {
+	return routePolicy(c.RoutePolicies, method, path)
+}
@@ -0,0 +1,70 @@
+package limiter
+
+import (
+	"time"
+
+	"rate-limiter/internal/storage"
+)
+
+// Decision is the outcome of checking a request against the policy that
+// matters most for it: the one that rejected it or, when it was allowed, the
+// one with the fewest requests left. Exempt, allowlisted and denylisted
+// requests have a zero Limit.
+type Decision struct {
+	Allowed bool
+	// Limit is how many requests Policy allows per Window.
+	Limit  int64
+	Window time.Duration
+	// Remaining is how many requests are left after this one.
+	Remaining int64
+	// Reset is when Policy is back to its full limit, or when the block
+	// rejecting the request ends.
+	Reset time.Duration
+	// RetryAfter, set only for rejected requests, is when a retry can
+	// succeed.
+	RetryAfter time.Duration
+	// Policy names the matched policy: "ip", "token", "tier:<name>",
+	// "route:<name>", "exempt", "allowlist" or "denylist".
+	Policy string
+}
+
+// Denied reports whether the request was rejected by the deny list rather
+// than by a limit, so retrying will not help.
+func (d *Decision) Denied() bool {
+	return d.Policy == "denylist"
+}
+
+// namedPolicy is a policy along with its name in decisions and the storage
+// key a client's requests are counted under.
+type namedPolicy struct {
+	Policy
+	name string
+	key  string
+}
+
+func (p namedPolicy) decision(result storage.Result) *Decision {
+	return &Decision{
+		Allowed:    result.Allowed,
+		Limit:      int64(p.Requests),
+		Window:     p.Window,
+		Remaining:  result.Remaining,
+		Reset:      result.ResetAfter,
+		RetryAfter: result.RetryAfter,
+		Policy:     p.name,
+	}
+}
+
+// blockedDecision rejects a request to p until blockUntil.
+func (p namedPolicy) blockedDecision(blockUntil time.Time) *Decision {
+	wait := time.Until(blockUntil)
+	if wait < 0 {
+		wait = 0
+	}
+	return &Decision{
+		Limit:      int64(p.Requests),
+		Window:     p.Window,
+		Reset:      wait,
+		RetryAfter: wait,
+		Policy:     p.name,
+	}
+}
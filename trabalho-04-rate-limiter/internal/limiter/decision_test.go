@@ -0,0 +1,84 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/storage"
+)
+
+func TestRateLimiter_CheckRequest_Decision(t *testing.T) {
+	config := &Config{
+		IPRequestsPerSecond:       2,
+		IPAlgorithm:               storage.GCRA,
+		TokenRequestsPerSecond:    1,
+		TokenBlockDurationMinutes: 1,
+		TokenTiers: []TokenTier{
+			{Name: "premium", Token: "abc123", Policy: Policy{Requests: 5, Window: time.Minute}},
+		},
+	}
+	limiter := NewRateLimiter(storage.NewMemoryStorage(), config)
+	ctx := context.Background()
+
+	decision, err := limiter.CheckRequest(ctx, "192.168.1.1", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := Decision{Allowed: true, Limit: 2, Window: time.Second, Remaining: 1, Reset: 500 * time.Millisecond, Policy: "ip"}
+	if *decision != want {
+		t.Errorf("Expected %+v, got %+v", want, *decision)
+	}
+
+	limiter.CheckRequest(ctx, "192.168.1.1", "")
+	decision, _ = limiter.CheckRequest(ctx, "192.168.1.1", "")
+	if decision.Allowed || decision.Remaining != 0 || decision.RetryAfter <= 0 || decision.RetryAfter > 500*time.Millisecond {
+		t.Errorf("Expected a rejection with a retry within 500ms, got %+v", *decision)
+	}
+
+	limiter.CheckRequest(ctx, "192.168.1.1", "other")
+	decision, _ = limiter.CheckRequest(ctx, "192.168.1.1", "other")
+	if decision.Allowed || decision.Policy != "token" || decision.RetryAfter < 59*time.Second || decision.Reset != decision.RetryAfter {
+		t.Errorf("Expected the token to be blocked for a minute, got %+v", *decision)
+	}
+	decision, _ = limiter.CheckRequest(ctx, "192.168.1.1", "other")
+	if decision.Allowed || decision.Policy != "token" || decision.RetryAfter < 59*time.Second {
+		t.Errorf("Expected the token block to reject, got %+v", *decision)
+	}
+
+	decision, _ = limiter.CheckRequest(ctx, "192.168.1.1", "abc123")
+	if !decision.Allowed || decision.Policy != "tier:premium" || decision.Limit != 5 || decision.Remaining != 4 {
+		t.Errorf("Expected the premium tier decision, got %+v", *decision)
+	}
+}
+
+func TestRateLimiter_Check_DecisionPicksTightestPolicy(t *testing.T) {
+	config := &Config{
+		IPRequestsPerSecond: 2,
+		RoutePolicies: []RoutePolicy{
+			{Path: "/health", Exempt: true},
+			{Method: "POST", Path: "/api/data", Policy: Policy{Requests: 10, Window: time.Second}},
+		},
+	}
+	limiter := NewRateLimiter(storage.NewMemoryStorage(), config)
+	ctx := context.Background()
+	post := Request{IP: "192.168.1.1", Method: "POST", Path: "/api/data"}
+
+	decision, _ := limiter.Check(ctx, post)
+	if decision.Policy != "ip" || decision.Remaining != 1 {
+		t.Errorf("Expected the IP policy with 1 remaining, got %+v", *decision)
+	}
+
+	config.RoutePolicies[1].Policy.Requests = 1
+	limiter = NewRateLimiter(storage.NewMemoryStorage(), config)
+	limiter.Check(ctx, post)
+	decision, _ = limiter.Check(ctx, post)
+	if decision.Allowed || decision.Policy != "route:POST /api/data" {
+		t.Errorf("Expected the route policy to reject, got %+v", *decision)
+	}
+
+	decision, _ = limiter.Check(ctx, Request{IP: "192.168.1.1", Method: "GET", Path: "/health"})
+	if *decision != (Decision{Allowed: true, Policy: "exempt"}) {
+		t.Errorf("Expected an exempt decision, got %+v", *decision)
+	}
+}
@@ -3,7 +3,7 @@ package limiter
 import (
 	"context"
 	"fmt"
-	"time"
+	"sync"
 
 	"rate-limiter/internal/storage"
 )
@@ -11,6 +11,9 @@ import (
 type RateLimiter struct {
 	storage storage.Storage
 	config  *Config
+	mutex   sync.RWMutex
+	// routes are the route policies registered with RegisterRoute.
+	routes []RoutePolicy
 }
 
 func NewRateLimiter(storage storage.Storage, config *Config) *RateLimiter {
@@ -20,88 +23,162 @@ func NewRateLimiter(storage storage.Storage, config *Config) *RateLimiter {
 	}
 }
 
-func (rl *RateLimiter) CheckRequest(ctx context.Context, ip, token string) (bool, error) {
-	if blocked, blockUntil, err := rl.storage.IsBlocked(ctx, fmt.Sprintf("ip:%s", ip)); err != nil {
-		return false, fmt.Errorf("failed to check IP block status: %w", err)
-	} else if blocked {
-		return false, fmt.Errorf("IP blocked until %v", blockUntil)
-	}
+// Config is the configuration the limiter was created with.
+func (rl *RateLimiter) Config() *Config {
+	return rl.config
+}
 
-	if token != "" {
-		if blocked, blockUntil, err := rl.storage.IsBlocked(ctx, fmt.Sprintf("token:%s", token)); err != nil {
-			return false, fmt.Errorf("failed to check token block status: %w", err)
-		} else if blocked {
-			return false, fmt.Errorf("token blocked until %v", blockUntil)
+func (rl *RateLimiter) CheckRequest(ctx context.Context, ip, token string) (*Decision, error) {
+	return rl.Check(ctx, Request{IP: ip, Token: token})
+}
+
+// Check rejects a client in the deny list, on any route. Otherwise it lets
+// req through when its route is exempt or the client is in the allow list,
+// and failing that both the route's policy, if it has one, and the client's
+// own policy must allow it.
+// A rejected request is not an error; errors come from the storage.
+func (rl *RateLimiter) Check(ctx context.Context, req Request) (*Decision, error) {
+	access := accessEntries(req.IP, req.Token)
+	route, hasRoute := rl.route(req)
+	if hasRoute && route.Exempt {
+		// Only the deny list applies; the allow list would change nothing.
+		result, err := rl.storage.Check(ctx, storage.Check{Access: access})
+		if err != nil {
+			return nil, fmt.Errorf("failed to check request: %w", err)
+		}
+		if result.Access == storage.DenyList {
+			return &Decision{Policy: "denylist"}, nil
 		}
+		return &Decision{Allowed: true, Policy: "exempt"}, nil
 	}
 
-	var requestsPerSecond int
-	var blockDuration time.Duration
-	var keyPrefix string
-
-	if token != "" {
-		requestsPerSecond = rl.config.TokenRequestsPerSecond
-		blockDuration = rl.config.GetTokenBlockDuration()
-		keyPrefix = fmt.Sprintf("token:%s", token)
-	} else {
-		requestsPerSecond = rl.config.IPRequestsPerSecond
-		blockDuration = rl.config.GetIPBlockDuration()
-		keyPrefix = fmt.Sprintf("ip:%s", ip)
+	client := rl.policy(req.IP, req.Token)
+
+	// Blocks and counters go to storage as one check, so concurrent requests
+	// cannot slip in between reading a counter and blocking its key. Each
+	// block and counter has its policy at the same index in blocked and
+	// counted.
+	ip := rl.policy(req.IP, "")
+	check := storage.Check{Access: access, Blocks: []string{ip.key}}
+	blocked := []namedPolicy{ip}
+	var counted []namedPolicy
+	if req.Token != "" {
+		check.Blocks = append(check.Blocks, client.key)
+		blocked = append(blocked, client)
 	}
+	if hasRoute {
+		routed := namedPolicy{
+			Policy: route.Policy,
+			name:   fmt.Sprintf("route:%s", route.scope()),
+			key:    routeKey(route, client),
+		}
+		check.Blocks = append(check.Blocks, routed.key)
+		blocked = append(blocked, routed)
+		check.Counters = append(check.Counters, routed.counter(routed.key))
+		counted = append(counted, routed)
+	}
+	check.Counters = append(check.Counters, client.counter(client.key))
+	counted = append(counted, client)
 
-	currentCount, err := rl.storage.Increment(ctx, keyPrefix, time.Second)
+	result, err := rl.storage.Check(ctx, check)
 	if err != nil {
-		return false, fmt.Errorf("failed to increment request count: %w", err)
+		return nil, fmt.Errorf("failed to check request: %w", err)
 	}
 
-	if currentCount > int64(requestsPerSecond) {
-		blockUntil := time.Now().Add(blockDuration)
-		if err := rl.storage.SetBlock(ctx, keyPrefix, blockUntil); err != nil {
-			return false, fmt.Errorf("failed to set block: %w", err)
-		}
-		return false, fmt.Errorf("rate limit exceeded, blocked until %v", blockUntil)
+	switch {
+	case result.Access == storage.DenyList:
+		return &Decision{Policy: "denylist"}, nil
+	case result.Access == storage.AllowList:
+		return &Decision{Allowed: true, Policy: "allowlist"}, nil
+	case result.Blocked >= 0:
+		return blocked[result.Blocked].blockedDecision(result.BlockUntil), nil
+	case result.Rejected >= 0 && !result.BlockUntil.IsZero():
+		return counted[result.Rejected].blockedDecision(result.BlockUntil), nil
+	case result.Rejected >= 0:
+		return counted[result.Rejected].decision(result.Results[result.Rejected]), nil
 	}
 
-	return true, nil
+	tightest := 0
+	for i, counter := range result.Results {
+		if counter.Remaining < result.Results[tightest].Remaining {
+			tightest = i
+		}
+	}
+	return counted[tightest].decision(result.Results[tightest]), nil
 }
 
 func (rl *RateLimiter) GetRemainingRequests(ctx context.Context, ip, token string) (int64, error) {
-	var requestsPerSecond int
-	var keyPrefix string
-
-	if token != "" {
-		requestsPerSecond = rl.config.TokenRequestsPerSecond
-		keyPrefix = fmt.Sprintf("token:%s", token)
-	} else {
-		requestsPerSecond = rl.config.IPRequestsPerSecond
-		keyPrefix = fmt.Sprintf("ip:%s", ip)
-	}
+	client := rl.policy(ip, token)
 
-	data, err := rl.storage.Get(ctx, keyPrefix)
+	result, err := rl.storage.Take(ctx, client.key, client.limit(), 0)
 	if err != nil {
 		return 0, err
 	}
 
-	if data == nil {
-		return int64(requestsPerSecond), nil
+	return result.Remaining, nil
+}
+
+// RegisterRoute makes Reset aware of a route policy that is not in the
+// configuration, such as one given to middleware.WithRoutePolicy.
+func (rl *RateLimiter) RegisterRoute(route RoutePolicy) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	rl.routes = append(rl.routes, route)
+}
+
+// Reset clears the counter and block of the client's own policy, those of the
+// client on every configured or registered route policy and, for a token,
+// those of its IP address, which blocks token requests too.
+func (rl *RateLimiter) Reset(ctx context.Context, ip, token string) error {
+	client := rl.policy(ip, token)
+	keys := []string{client.key}
+	if token != "" {
+		keys = append(keys, rl.policy(ip, "").key)
 	}
 
-	remaining := int64(requestsPerSecond) - data.Count
-	if remaining < 0 {
-		return 0, nil
+	rl.mutex.RLock()
+	routes := append(append([]RoutePolicy(nil), rl.config.RoutePolicies...), rl.routes...)
+	rl.mutex.RUnlock()
+	seen := make(map[string]bool)
+	for _, route := range routes {
+		key := routeKey(route, client)
+		if route.Exempt || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
 	}
 
-	return remaining, nil
+	for _, key := range keys {
+		if err := rl.storage.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (rl *RateLimiter) Reset(ctx context.Context, ip, token string) error {
-	var keyPrefix string
+// routeKey keeps the client's counter on route apart from its own counter and
+// from its counters on other routes.
+func routeKey(route RoutePolicy, client namedPolicy) string {
+	return fmt.Sprintf("route:%s:%s", route.scope(), client.key)
+}
 
-	if token != "" {
-		keyPrefix = fmt.Sprintf("token:%s", token)
-	} else {
-		keyPrefix = fmt.Sprintf("ip:%s", ip)
+// policy picks the token's policy when a token is given and the IP policy
+// otherwise.
+func (rl *RateLimiter) policy(ip, token string) namedPolicy {
+	if token == "" {
+		return namedPolicy{Policy: rl.config.IPPolicy(), name: "ip", key: fmt.Sprintf("ip:%s", ip)}
 	}
+	if tier, ok := tokenTier(rl.config.TokenTiers, token); ok {
+		return namedPolicy{Policy: tier.Policy, name: fmt.Sprintf("tier:%s", tier.Name), key: fmt.Sprintf("token:%s", token)}
+	}
+	return namedPolicy{Policy: rl.config.TokenPolicy(), name: "token", key: fmt.Sprintf("token:%s", token)}
+}
 
-	return rl.storage.Delete(ctx, keyPrefix)
+// route is the route policy req falls under, if any.
+func (rl *RateLimiter) route(req Request) (RoutePolicy, bool) {
+	if req.Route != nil {
+		return *req.Route, true
+	}
+	return rl.config.RoutePolicyFor(req.Method, req.Path)
 }
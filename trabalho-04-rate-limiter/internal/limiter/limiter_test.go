@@ -2,6 +2,7 @@ package limiter
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,27 +15,27 @@ func TestRateLimiter_CheckRequest_IP(t *testing.T) {
 		IPRequestsPerSecond:    2,
 		IPBlockDurationMinutes: 1,
 	}
-	
+
 	limiter := NewRateLimiter(storage, config)
 	ctx := context.Background()
-	
+
 	// Test normal requests within limit
 	for i := 0; i < 2; i++ {
-		allowed, err := limiter.CheckRequest(ctx, "192.168.1.1", "")
+		decision, err := limiter.CheckRequest(ctx, "192.168.1.1", "")
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
-		if !allowed {
+		if !decision.Allowed {
 			t.Errorf("Expected request %d to be allowed", i+1)
 		}
 	}
-	
+
 	// Test request that exceeds limit
-	allowed, err := limiter.CheckRequest(ctx, "192.168.1.1", "")
-	if err == nil {
-		t.Error("Expected error for exceeding rate limit")
+	decision, err := limiter.CheckRequest(ctx, "192.168.1.1", "")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
 	}
-	if allowed {
+	if decision.Allowed {
 		t.Error("Expected request to be blocked")
 	}
 }
@@ -45,27 +46,27 @@ func TestRateLimiter_CheckRequest_Token(t *testing.T) {
 		TokenRequestsPerSecond:    3,
 		TokenBlockDurationMinutes: 1,
 	}
-	
+
 	limiter := NewRateLimiter(storage, config)
 	ctx := context.Background()
-	
+
 	// Test normal requests within limit
 	for i := 0; i < 3; i++ {
-		allowed, err := limiter.CheckRequest(ctx, "192.168.1.1", "token123")
+		decision, err := limiter.CheckRequest(ctx, "192.168.1.1", "token123")
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
-		if !allowed {
+		if !decision.Allowed {
 			t.Errorf("Expected request %d to be allowed", i+1)
 		}
 	}
-	
+
 	// Test request that exceeds limit
-	allowed, err := limiter.CheckRequest(ctx, "192.168.1.1", "token123")
-	if err == nil {
-		t.Error("Expected error for exceeding rate limit")
+	decision, err := limiter.CheckRequest(ctx, "192.168.1.1", "token123")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
 	}
-	if allowed {
+	if decision.Allowed {
 		t.Error("Expected request to be blocked")
 	}
 }
@@ -78,36 +79,36 @@ func TestRateLimiter_TokenOverridesIP(t *testing.T) {
 		TokenRequestsPerSecond:    5,
 		TokenBlockDurationMinutes: 1,
 	}
-	
+
 	limiter := NewRateLimiter(storage, config)
 	ctx := context.Background()
-	
+
 	// Test that token allows more requests than IP limit
 	for i := 0; i < 5; i++ {
-		allowed, err := limiter.CheckRequest(ctx, "192.168.1.1", "token123")
+		decision, err := limiter.CheckRequest(ctx, "192.168.1.1", "token123")
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
-		if !allowed {
+		if !decision.Allowed {
 			t.Errorf("Expected request %d to be allowed with token", i+1)
 		}
 	}
-	
+
 	// Test that without token, IP limit applies
-	allowed, err := limiter.CheckRequest(ctx, "192.168.1.1", "")
+	decision, err := limiter.CheckRequest(ctx, "192.168.1.1", "")
 	if err != nil {
 		t.Errorf("Expected no error for first IP request, got %v", err)
 	}
-	if !allowed {
+	if !decision.Allowed {
 		t.Error("Expected first IP request to be allowed")
 	}
-	
+
 	// Second IP request should be blocked
-	allowed, err = limiter.CheckRequest(ctx, "192.168.1.1", "")
-	if err == nil {
-		t.Error("Expected error for exceeding IP rate limit")
+	decision, err = limiter.CheckRequest(ctx, "192.168.1.1", "")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
 	}
-	if allowed {
+	if decision.Allowed {
 		t.Error("Expected second IP request to be blocked")
 	}
 }
@@ -118,10 +119,10 @@ func TestRateLimiter_GetRemainingRequests(t *testing.T) {
 		IPRequestsPerSecond:    3,
 		IPBlockDurationMinutes: 1,
 	}
-	
+
 	limiter := NewRateLimiter(storage, config)
 	ctx := context.Background()
-	
+
 	// Initially should have all requests available
 	remaining, err := limiter.GetRemainingRequests(ctx, "192.168.1.1", "")
 	if err != nil {
@@ -130,13 +131,13 @@ func TestRateLimiter_GetRemainingRequests(t *testing.T) {
 	if remaining != 3 {
 		t.Errorf("Expected 3 remaining requests, got %d", remaining)
 	}
-	
+
 	// Make one request
 	_, err = limiter.CheckRequest(ctx, "192.168.1.1", "")
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	// Should have 2 remaining
 	remaining, err = limiter.GetRemainingRequests(ctx, "192.168.1.1", "")
 	if err != nil {
@@ -153,41 +154,47 @@ func TestRateLimiter_Reset(t *testing.T) {
 		IPRequestsPerSecond:    1,
 		IPBlockDurationMinutes: 1,
 	}
-	
+
 	limiter := NewRateLimiter(storage, config)
 	ctx := context.Background()
-	
+
 	// Make request to use up limit
 	_, err := limiter.CheckRequest(ctx, "192.168.1.1", "")
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	// Second request should be blocked
-	_, err = limiter.CheckRequest(ctx, "192.168.1.1", "")
-	if err == nil {
-		t.Error("Expected error for exceeding rate limit")
+	decision, err := limiter.CheckRequest(ctx, "192.168.1.1", "")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
 	}
-	
+	if decision.Allowed {
+		t.Error("Expected second request to be blocked")
+	}
+
 	// Reset the limit
 	err = limiter.Reset(ctx, "192.168.1.1", "")
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	// Should be able to make request again
-	allowed, err := limiter.CheckRequest(ctx, "192.168.1.1", "")
+	decision, err = limiter.CheckRequest(ctx, "192.168.1.1", "")
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if !allowed {
+	if !decision.Allowed {
 		t.Error("Expected request to be allowed after reset")
 	}
 }
 
 func TestConfig_LoadConfig(t *testing.T) {
-	config := LoadConfig()
-	
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
 	// Test default values
 	if config.IPRequestsPerSecond == 0 {
 		t.Error("Expected IPRequestsPerSecond to have a default value")
@@ -208,14 +215,151 @@ func TestConfig_GetDurations(t *testing.T) {
 		IPBlockDurationMinutes:    5,
 		TokenBlockDurationMinutes: 10,
 	}
-	
+
 	ipDuration := config.GetIPBlockDuration()
 	if ipDuration != 5*time.Minute {
 		t.Errorf("Expected IP block duration to be 5 minutes, got %v", ipDuration)
 	}
-	
+
 	tokenDuration := config.GetTokenBlockDuration()
 	if tokenDuration != 10*time.Minute {
 		t.Errorf("Expected token block duration to be 10 minutes, got %v", tokenDuration)
 	}
 }
+
+func TestRateLimiter_CheckRequest_Algorithms(t *testing.T) {
+	algorithms := []storage.Algorithm{
+		storage.FixedWindow,
+		storage.SlidingWindowCounter,
+		storage.SlidingLog,
+		storage.TokenBucket,
+		storage.GCRA,
+	}
+
+	for _, algorithm := range algorithms {
+		t.Run(string(algorithm), func(t *testing.T) {
+			limiter := NewRateLimiter(storage.NewMemoryStorage(), &Config{
+				IPRequestsPerSecond: 2,
+				IPAlgorithm:         algorithm,
+			})
+			ctx := context.Background()
+
+			for i := 0; i < 2; i++ {
+				if decision, err := limiter.CheckRequest(ctx, "192.168.1.1", ""); err != nil || !decision.Allowed {
+					t.Fatalf("Expected request %d to be allowed, got %v", i+1, err)
+				}
+			}
+
+			decision, err := limiter.CheckRequest(ctx, "192.168.1.1", "")
+			if err != nil || decision.Allowed {
+				t.Error("Expected request over the limit to be rejected")
+			}
+
+			remaining, err := limiter.GetRemainingRequests(ctx, "192.168.1.1", "")
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if remaining != 0 {
+				t.Errorf("Expected 0 remaining requests, got %d", remaining)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_ZeroBlockDurationOnlyRejectsExcess(t *testing.T) {
+	storage := storage.NewMemoryStorage()
+	limiter := NewRateLimiter(storage, &Config{IPRequestsPerSecond: 1})
+	ctx := context.Background()
+
+	limiter.CheckRequest(ctx, "192.168.1.1", "")
+	if decision, _ := limiter.CheckRequest(ctx, "192.168.1.1", ""); decision.Allowed {
+		t.Fatal("Expected request over the limit to be rejected")
+	}
+
+	blocked, _, err := storage.IsBlocked(ctx, "ip:192.168.1.1")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if blocked {
+		t.Error("Expected no block without a block duration")
+	}
+}
+
+func TestConfig_LoadConfigAlgorithms(t *testing.T) {
+	t.Setenv("RATE_LIMIT_ALGORITHM", "sliding_log")
+	t.Setenv("RATE_LIMIT_TOKEN_ALGORITHM", "gcra")
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.IPAlgorithm != storage.SlidingLog {
+		t.Errorf("Expected IP algorithm sliding_log, got %q", config.IPAlgorithm)
+	}
+	if config.TokenAlgorithm != storage.GCRA {
+		t.Errorf("Expected token algorithm gcra, got %q", config.TokenAlgorithm)
+	}
+
+	for _, key := range []string{"RATE_LIMIT_ALGORITHM", "RATE_LIMIT_IP_ALGORITHM", "RATE_LIMIT_TOKEN_ALGORITHM"} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, "gcra2")
+			if _, err := LoadConfig(); err == nil || !strings.Contains(err.Error(), key) {
+				t.Errorf("Expected an unknown algorithm in %s to be rejected, got %v", key, err)
+			}
+		})
+	}
+}
+
+func TestConfig_LoadConfigClientIP(t *testing.T) {
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(config.TrustedProxies) != 0 || config.IPv6PrefixLength != 64 || config.ClientIPHeader != ClientIPHeaderXFF {
+		t.Errorf("Expected no trusted proxies, a /64 prefix and X-Forwarded-For by default, got %+v", config)
+	}
+
+	t.Setenv("RATE_LIMIT_TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.1,2001:db8::1/48")
+	t.Setenv("RATE_LIMIT_IPV6_PREFIX", "56")
+	config, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []string{"10.0.0.0/8", "192.168.1.1/32", "2001:db8::/48"}
+	if len(config.TrustedProxies) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, config.TrustedProxies)
+	}
+	for i, prefix := range config.TrustedProxies {
+		if prefix.String() != want[i] {
+			t.Errorf("Expected %s, got %s", want[i], prefix)
+		}
+	}
+	if config.IPv6PrefixLength != 56 {
+		t.Errorf("Expected a /56 prefix, got /%d", config.IPv6PrefixLength)
+	}
+
+	t.Setenv("RATE_LIMIT_CLIENT_IP_HEADER", "Forwarded")
+	if config, err := LoadConfig(); err != nil || config.ClientIPHeader != ClientIPHeaderForwarded {
+		t.Errorf("Expected the Forwarded header, got %v", err)
+	}
+	t.Setenv("RATE_LIMIT_CLIENT_IP_HEADER", "true-client-ip")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected an unknown client IP header to fail")
+	}
+	t.Setenv("RATE_LIMIT_CLIENT_IP_HEADER", "")
+
+	t.Setenv("RATE_LIMIT_TRUSTED_PROXIES", "10.0.0.0/33")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected an invalid trusted proxy to fail")
+	}
+
+	t.Setenv("RATE_LIMIT_TRUSTED_PROXIES", "")
+	t.Setenv("RATE_LIMIT_IPV6_PREFIX", "0")
+	if config, err := LoadConfig(); err != nil || config.IPv6PrefixLength != 0 {
+		t.Errorf("Expected 0 to be accepted as per-address limiting, got %v", err)
+	}
+	t.Setenv("RATE_LIMIT_IPV6_PREFIX", "129")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected an invalid IPv6 prefix length to fail")
+	}
+}
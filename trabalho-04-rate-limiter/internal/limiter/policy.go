@@ -0,0 +1,34 @@
+package limiter
+
+import (
+	"time"
+
+	"rate-limiter/internal/storage"
+)
+
+// Policy allows Requests per Window, counted with Algorithm (fixed window
+// when empty). A client going over it is blocked for BlockDuration; with a
+// zero BlockDuration only the requests over the limit are rejected.
+type Policy struct {
+	Algorithm     storage.Algorithm
+	Requests      int
+	Window        time.Duration
+	BlockDuration time.Duration
+}
+
+func (p Policy) limit() storage.Limit {
+	algorithm := p.Algorithm
+	if algorithm == "" {
+		algorithm = storage.FixedWindow
+	}
+
+	return storage.Limit{
+		Algorithm: algorithm,
+		Requests:  int64(p.Requests),
+		Window:    p.Window,
+	}
+}
+
+func (p Policy) counter(key string) storage.Counter {
+	return storage.Counter{Key: key, Limit: p.limit(), BlockDuration: p.BlockDuration}
+}
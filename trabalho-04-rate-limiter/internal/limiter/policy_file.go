@@ -0,0 +1,219 @@
+package limiter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"rate-limiter/internal/storage"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TokenTier gives the token equal to Token, or every token starting with
+// Prefix, the policy of the tier called Name instead of the default token
+// policy. Each token is still counted on its own.
+type TokenTier struct {
+	Name   string
+	Token  string
+	Prefix string
+	Policy Policy
+}
+
+// policyFile is the layout of RATE_LIMIT_POLICY_FILE. JSON files work too,
+// since JSON is valid YAML.
+//
+//	tiers:
+//	  premium:
+//	    requests: 100
+//	    window: 1s
+//	    block_duration: 1m
+//	    algorithm: token_bucket
+//	tokens:
+//	  - token: abc123
+//	    tier: premium
+//	  - prefix: partner-
+//	    tier: premium
+//	routes:
+//	  - method: POST
+//	    path: /api/data
+//	    requests: 2
+//	  - path: /health
+//	    exempt: true
+type policyFile struct {
+	Tiers  map[string]tierSpec `yaml:"tiers"`
+	Tokens []tokenSpec         `yaml:"tokens"`
+	Routes []routeSpec         `yaml:"routes"`
+}
+
+// tierSpec fields left out fall back to a one second window and to the
+// token block duration and algorithm from the environment.
+type tierSpec struct {
+	Requests      int    `yaml:"requests"`
+	Window        string `yaml:"window"`
+	BlockDuration string `yaml:"block_duration"`
+	Algorithm     string `yaml:"algorithm"`
+}
+
+type tokenSpec struct {
+	Token  string `yaml:"token"`
+	Prefix string `yaml:"prefix"`
+	Tier   string `yaml:"tier"`
+}
+
+// routeSpec takes the same limit fields as a tier; the ones left out fall
+// back to a one second window, no block and the default algorithm.
+type routeSpec struct {
+	Name     string `yaml:"name"`
+	Method   string `yaml:"method"`
+	Path     string `yaml:"path"`
+	Exempt   bool   `yaml:"exempt"`
+	tierSpec `yaml:",inline"`
+}
+
+// policies is what a policy file configures.
+type policies struct {
+	tiers  []TokenTier
+	routes []RoutePolicy
+}
+
+// loadPolicyFile reads the policy file at path, using tierDefaults and
+// routeDefaults for the fields a tier or route leaves out.
+func loadPolicyFile(path string, tierDefaults, routeDefaults Policy) (*policies, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var file policyFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s: %w", path, err)
+	}
+
+	tiers, err := file.tokenTiers(tierDefaults)
+	if err != nil {
+		return nil, fmt.Errorf("policy file %s: %w", path, err)
+	}
+	routes, err := file.routePolicies(routeDefaults)
+	if err != nil {
+		return nil, fmt.Errorf("policy file %s: %w", path, err)
+	}
+
+	return &policies{tiers: tiers, routes: routes}, nil
+}
+
+func (file policyFile) tokenTiers(defaults Policy) ([]TokenTier, error) {
+	tierPolicies := make(map[string]Policy, len(file.Tiers))
+	for name, spec := range file.Tiers {
+		policy, err := spec.policy(defaults)
+		if err != nil {
+			return nil, fmt.Errorf("tier %q: %w", name, err)
+		}
+		tierPolicies[name] = policy
+	}
+
+	tiers := make([]TokenTier, 0, len(file.Tokens))
+	seen := make(map[string]bool, len(file.Tokens))
+	for i, spec := range file.Tokens {
+		if (spec.Token == "") == (spec.Prefix == "") {
+			return nil, fmt.Errorf("token %d: exactly one of token and prefix must be set", i+1)
+		}
+		policy, ok := tierPolicies[spec.Tier]
+		if !ok {
+			return nil, fmt.Errorf("token %d: unknown tier %q", i+1, spec.Tier)
+		}
+		match := "token:" + spec.Token
+		if spec.Prefix != "" {
+			match = "prefix:" + spec.Prefix
+		}
+		if seen[match] {
+			return nil, fmt.Errorf("token %d: %s is listed twice", i+1, match)
+		}
+		seen[match] = true
+
+		tiers = append(tiers, TokenTier{Name: spec.Tier, Token: spec.Token, Prefix: spec.Prefix, Policy: policy})
+	}
+
+	return tiers, nil
+}
+
+func (file policyFile) routePolicies(defaults Policy) ([]RoutePolicy, error) {
+	routes := make([]RoutePolicy, 0, len(file.Routes))
+	for i, spec := range file.Routes {
+		if !strings.HasPrefix(spec.Path, "/") {
+			return nil, fmt.Errorf("route %d: path must start with /, got %q", i+1, spec.Path)
+		}
+		route := RoutePolicy{
+			Name:   spec.Name,
+			Method: strings.ToUpper(spec.Method),
+			Path:   spec.Path,
+			Exempt: spec.Exempt,
+		}
+		if !spec.Exempt {
+			policy, err := spec.policy(defaults)
+			if err != nil {
+				return nil, fmt.Errorf("route %d (%s): %w", i+1, route.scope(), err)
+			}
+			route.Policy = policy
+		}
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+func (s tierSpec) policy(defaults Policy) (Policy, error) {
+	policy := defaults
+	policy.Window = time.Second
+
+	if s.Requests <= 0 {
+		return Policy{}, fmt.Errorf("requests must be positive, got %d", s.Requests)
+	}
+	policy.Requests = s.Requests
+
+	if s.Window != "" {
+		window, err := time.ParseDuration(s.Window)
+		if err != nil || window < time.Millisecond {
+			return Policy{}, fmt.Errorf("invalid window %q", s.Window)
+		}
+		policy.Window = window
+	}
+
+	if s.BlockDuration != "" {
+		blockDuration, err := time.ParseDuration(s.BlockDuration)
+		if err != nil || blockDuration < 0 {
+			return Policy{}, fmt.Errorf("invalid block_duration %q", s.BlockDuration)
+		}
+		policy.BlockDuration = blockDuration
+	}
+
+	if s.Algorithm != "" {
+		algorithm, err := storage.ParseAlgorithm(s.Algorithm)
+		if err != nil {
+			return Policy{}, err
+		}
+		policy.Algorithm = algorithm
+	}
+
+	return policy, nil
+}
+
+// tokenTier finds the tier of token: an exact match first, then the
+// longest matching prefix.
+func tokenTier(tiers []TokenTier, token string) (TokenTier, bool) {
+	var best TokenTier
+	found := false
+	for _, tier := range tiers {
+		if tier.Token != "" {
+			if tier.Token == token {
+				return tier, true
+			}
+			continue
+		}
+		if strings.HasPrefix(token, tier.Prefix) && (!found || len(tier.Prefix) > len(best.Prefix)) {
+			best, found = tier, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,132 @@
+package limiter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/storage"
+)
+
+const testPolicyFile = `
+tiers:
+  premium:
+    requests: 100
+    window: 1s
+    block_duration: 1m
+    algorithm: token_bucket
+  partner:
+    requests: 20
+    window: 10s
+  trial:
+    requests: 2
+    block_duration: 0s
+tokens:
+  - token: abc123
+    tier: premium
+  - prefix: partner-
+    tier: partner
+  - prefix: partner-trial-
+    tier: trial
+`
+
+func writePolicyFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write policy file: %v", err)
+	}
+	return path
+}
+
+func TestConfig_LoadConfigTokenTiers(t *testing.T) {
+	t.Setenv("RATE_LIMIT_TOKEN_REQUESTS_PER_SECOND", "10")
+	t.Setenv("RATE_LIMIT_TOKEN_BLOCK_DURATION_MINUTES", "5")
+	t.Setenv("RATE_LIMIT_POLICY_FILE", writePolicyFile(t, "policies.yaml", testPolicyFile))
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := map[string]Policy{
+		"abc123":             {Algorithm: storage.TokenBucket, Requests: 100, Window: time.Second, BlockDuration: time.Minute},
+		"partner-acme":       {Algorithm: storage.FixedWindow, Requests: 20, Window: 10 * time.Second, BlockDuration: 5 * time.Minute},
+		"partner-trial-acme": {Algorithm: storage.FixedWindow, Requests: 2, Window: time.Second},
+		"abc1234":            config.TokenPolicy(),
+		"someone-else":       config.TokenPolicy(),
+		"partner":            config.TokenPolicy(),
+	}
+	for token, want := range tests {
+		if got := config.TokenPolicyFor(token); got != want {
+			t.Errorf("Expected policy %+v for %s, got %+v", want, token, got)
+		}
+	}
+}
+
+func TestConfig_LoadConfigTokenTiersFromJSON(t *testing.T) {
+	t.Setenv("RATE_LIMIT_POLICY_FILE", writePolicyFile(t, "policies.json",
+		`{"tiers": {"gold": {"requests": 50}}, "tokens": [{"token": "abc123", "tier": "gold"}]}`))
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if policy := config.TokenPolicyFor("abc123"); policy.Requests != 50 {
+		t.Errorf("Expected 50 requests for abc123, got %d", policy.Requests)
+	}
+}
+
+func TestConfig_LoadConfigRejectsInvalidPolicyFiles(t *testing.T) {
+	tests := map[string]string{
+		"unknown tier":      "tokens:\n  - token: abc\n    tier: gold\n",
+		"no requests":       "tiers:\n  gold:\n    window: 1s\n",
+		"invalid window":    "tiers:\n  gold:\n    requests: 1\n    window: soon\n",
+		"invalid algorithm": "tiers:\n  gold:\n    requests: 1\n    algorithm: leaky\n",
+		"token and prefix":  "tiers:\n  gold:\n    requests: 1\ntokens:\n  - token: abc\n    prefix: a\n    tier: gold\n",
+		"duplicate token":   "tiers:\n  gold:\n    requests: 1\ntokens:\n  - token: abc\n    tier: gold\n  - token: abc\n    tier: gold\n",
+		"malformed":         "tiers: [",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("RATE_LIMIT_POLICY_FILE", writePolicyFile(t, "policies.yaml", content))
+			if _, err := LoadConfig(); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	t.Setenv("RATE_LIMIT_POLICY_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected an error for a missing policy file")
+	}
+}
+
+func TestRateLimiter_CheckRequest_TokenTier(t *testing.T) {
+	config := &Config{
+		TokenRequestsPerSecond:    1,
+		TokenBlockDurationMinutes: 1,
+		TokenTiers: []TokenTier{
+			{Name: "premium", Token: "abc123", Policy: Policy{Requests: 3, Window: time.Second}},
+		},
+	}
+	limiter := NewRateLimiter(storage.NewMemoryStorage(), config)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if decision, err := limiter.CheckRequest(ctx, "192.168.1.1", "abc123"); err != nil || !decision.Allowed {
+			t.Fatalf("Expected request %d with the premium token to be allowed, got %v", i+1, err)
+		}
+	}
+	if decision, _ := limiter.CheckRequest(ctx, "192.168.1.1", "abc123"); decision.Allowed {
+		t.Error("Expected the fourth request with the premium token to be rejected")
+	}
+
+	if decision, _ := limiter.CheckRequest(ctx, "192.168.1.1", "other"); !decision.Allowed {
+		t.Error("Expected the first request with another token to be allowed")
+	}
+	if decision, _ := limiter.CheckRequest(ctx, "192.168.1.1", "other"); decision.Allowed {
+		t.Error("Expected the second request with another token to be rejected")
+	}
+}
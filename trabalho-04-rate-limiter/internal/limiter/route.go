@@ -0,0 +1,61 @@
+package limiter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RoutePolicy limits the requests to the routes matching Method (any method
+// when empty) and Path, which is either exact or, ending in "*", a prefix.
+// Its counters are kept per client and per Name, on top of the client's own
+// limit. Exempt routes skip rate limiting; only the deny list still applies.
+type RoutePolicy struct {
+	Name   string
+	Method string
+	Path   string
+	Exempt bool
+	Policy Policy
+}
+
+// Request is what the limiter checks. Route, when set, is used instead of
+// the configured route policies.
+type Request struct {
+	IP     string
+	Token  string
+	Method string
+	Path   string
+	Route  *RoutePolicy
+}
+
+func (r RoutePolicy) matches(method, path string) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(r.Path, "*"); ok {
+		return strings.HasPrefix(path, prefix)
+	}
+	return r.Path == path
+}
+
+// scope is the part of the storage key that keeps the route's counters
+// apart from other routes'.
+func (r RoutePolicy) scope() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	method := r.Method
+	if method == "" {
+		method = "*"
+	}
+	return fmt.Sprintf("%s %s", strings.ToUpper(method), r.Path)
+}
+
+// routePolicy finds the first of routes matching method and path.
+func routePolicy(routes []RoutePolicy, method, path string) (RoutePolicy, bool) {
+	for _, route := range routes {
+		if route.matches(method, path) {
+			return route, true
+		}
+	}
+	return RoutePolicy{}, false
+}
@@ -0,0 +1,179 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/storage"
+)
+
+func TestRoutePolicy_Matches(t *testing.T) {
+	tests := []struct {
+		route  RoutePolicy
+		method string
+		path   string
+		want   bool
+	}{
+		{RoutePolicy{Method: "POST", Path: "/api/data"}, "POST", "/api/data", true},
+		{RoutePolicy{Method: "post", Path: "/api/data"}, "POST", "/api/data", true},
+		{RoutePolicy{Method: "POST", Path: "/api/data"}, "GET", "/api/data", false},
+		{RoutePolicy{Method: "POST", Path: "/api/data"}, "POST", "/api/data/1", false},
+		{RoutePolicy{Path: "/health"}, "HEAD", "/health", true},
+		{RoutePolicy{Path: "/api/*"}, "GET", "/api/users/:id", true},
+		{RoutePolicy{Path: "/api/*"}, "GET", "/apis", false},
+		{RoutePolicy{Path: "*"}, "DELETE", "/anything", true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.route.matches(tt.method, tt.path); got != tt.want {
+			t.Errorf("Expected %+v matching %s %s to be %v", tt.route, tt.method, tt.path, tt.want)
+		}
+	}
+}
+
+func TestRateLimiter_Check_RoutePolicies(t *testing.T) {
+	config := &Config{
+		IPRequestsPerSecond: 3,
+		RoutePolicies: []RoutePolicy{
+			{Path: "/health", Exempt: true},
+			{Method: "POST", Path: "/api/data", Policy: Policy{Requests: 1, Window: time.Second, BlockDuration: time.Minute}},
+		},
+	}
+	limiter := NewRateLimiter(storage.NewMemoryStorage(), config)
+	ctx := context.Background()
+	post := Request{IP: "192.168.1.1", Method: "POST", Path: "/api/data"}
+	get := Request{IP: "192.168.1.1", Method: "GET", Path: "/api/data"}
+
+	if decision, err := limiter.Check(ctx, post); err != nil || !decision.Allowed {
+		t.Fatalf("Expected the first POST to be allowed, got %v", err)
+	}
+	if decision, _ := limiter.Check(ctx, post); decision.Allowed {
+		t.Error("Expected the second POST to be rejected by the route policy")
+	}
+
+	// The rejected POST did not count against the IP, which has 2 left.
+	for i := 0; i < 2; i++ {
+		if decision, err := limiter.Check(ctx, get); err != nil || !decision.Allowed {
+			t.Fatalf("Expected GET %d to be allowed, got %v", i+1, err)
+		}
+	}
+	if decision, _ := limiter.Check(ctx, get); decision.Allowed {
+		t.Error("Expected the IP limit to apply to GET")
+	}
+
+	for i := 0; i < 5; i++ {
+		health := Request{IP: "192.168.1.1", Method: "GET", Path: "/health"}
+		if decision, err := limiter.Check(ctx, health); err != nil || !decision.Allowed {
+			t.Errorf("Expected /health to be exempt, got %v", err)
+		}
+	}
+}
+
+func TestRateLimiter_Check_ExplicitRoute(t *testing.T) {
+	config := &Config{
+		IPRequestsPerSecond: 10,
+		RoutePolicies:       []RoutePolicy{{Path: "*", Exempt: true}},
+	}
+	limiter := NewRateLimiter(storage.NewMemoryStorage(), config)
+	ctx := context.Background()
+	admin := &RoutePolicy{Name: "admin", Policy: Policy{Requests: 1, Window: time.Second}}
+
+	req := Request{IP: "192.168.1.1", Method: "GET", Path: "/admin/users", Route: admin}
+	if decision, err := limiter.Check(ctx, req); err != nil || !decision.Allowed {
+		t.Fatalf("Expected the first request to be allowed, got %v", err)
+	}
+	req.Path = "/admin/settings"
+	if decision, _ := limiter.Check(ctx, req); decision.Allowed {
+		t.Error("Expected the group's counter to be shared by its routes")
+	}
+}
+
+func TestConfig_LoadConfigRoutePolicies(t *testing.T) {
+	t.Setenv("RATE_LIMIT_ALGORITHM", "gcra")
+	t.Setenv("RATE_LIMIT_POLICY_FILE", writePolicyFile(t, "policies.yaml", `
+routes:
+  - method: post
+    path: /api/data
+    requests: 2
+    window: 10s
+  - path: /health
+    exempt: true
+  - name: api
+    path: /api/*
+    requests: 20
+    block_duration: 30s
+    algorithm: sliding_log
+`))
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		method, path string
+		want         RoutePolicy
+	}{
+		{"POST", "/api/data", RoutePolicy{Method: "POST", Path: "/api/data", Policy: Policy{Algorithm: storage.GCRA, Requests: 2, Window: 10 * time.Second}}},
+		{"GET", "/health", RoutePolicy{Path: "/health", Exempt: true}},
+		{"GET", "/api/data", RoutePolicy{Name: "api", Path: "/api/*", Policy: Policy{Algorithm: storage.SlidingLog, Requests: 20, Window: time.Second, BlockDuration: 30 * time.Second}}},
+	}
+	for _, tt := range tests {
+		got, ok := config.RoutePolicyFor(tt.method, tt.path)
+		if !ok || got != tt.want {
+			t.Errorf("Expected %+v for %s %s, got %+v", tt.want, tt.method, tt.path, got)
+		}
+	}
+	if _, ok := config.RoutePolicyFor("GET", "/other"); ok {
+		t.Error("Expected no route policy for /other")
+	}
+
+	t.Setenv("RATE_LIMIT_POLICY_FILE", writePolicyFile(t, "invalid.yaml", "routes:\n  - path: /api/data\n"))
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected an error for a route without requests")
+	}
+}
+
+func TestRateLimiter_Reset_RoutePolicies(t *testing.T) {
+	config := &Config{
+		IPRequestsPerSecond:       1,
+		IPBlockDurationMinutes:    1,
+		TokenRequestsPerSecond:    10,
+		TokenBlockDurationMinutes: 1,
+		RoutePolicies: []RoutePolicy{
+			{Method: "POST", Path: "/api/data", Policy: Policy{Requests: 1, Window: time.Minute, BlockDuration: time.Minute}},
+		},
+	}
+	limiter := NewRateLimiter(storage.NewMemoryStorage(), config)
+	group := RoutePolicy{Name: "reports", Policy: Policy{Requests: 1, Window: time.Minute}}
+	limiter.RegisterRoute(group)
+	ctx := context.Background()
+
+	// Use up both routes with a token, then get its IP blocked.
+	requests := []Request{
+		{IP: "192.168.1.1", Token: "abc", Method: "POST", Path: "/api/data"},
+		{IP: "192.168.1.1", Token: "abc", Method: "GET", Path: "/reports", Route: &group},
+	}
+	for _, req := range requests {
+		limiter.Check(ctx, req)
+		if decision, _ := limiter.Check(ctx, req); decision.Allowed {
+			t.Fatalf("Expected %s %s to be rejected before the reset", req.Method, req.Path)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		limiter.CheckRequest(ctx, "192.168.1.1", "")
+	}
+	if decision, _ := limiter.CheckRequest(ctx, "192.168.1.1", "abc"); decision.Allowed {
+		t.Fatal("Expected the IP block to apply to the token")
+	}
+
+	if err := limiter.Reset(ctx, "192.168.1.1", "abc"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, req := range append(requests, Request{IP: "192.168.1.1", Token: "abc", Method: "GET", Path: "/"}) {
+		if decision, err := limiter.Check(ctx, req); err != nil || !decision.Allowed {
+			t.Errorf("Expected %s %s to be allowed after the reset, got %+v (err %v)", req.Method, req.Path, decision, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"rate-limiter/internal/limiter"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientIP is the address the request is rate limited by. Only
+// config.ClientIPHeader is read, only when the request comes from one of
+// config's trusted proxies, and right to left, skipping trusted proxies, so
+// a client cannot pick its own address by sending forwarding headers. IPv6
+// addresses are grouped into networks of config.IPv6PrefixLength bits, such
+// as 2001:db8::/64.
+func ClientIP(c *gin.Context, config *limiter.Config) string {
+	addr := clientAddr(c.Request, config.TrustedProxies, config.ClientIPHeader)
+	if !addr.IsValid() {
+		return "127.0.0.1"
+	}
+
+	if addr.Is6() && config.IPv6PrefixLength > 0 && config.IPv6PrefixLength < 128 {
+		prefix, err := addr.Prefix(config.IPv6PrefixLength)
+		if err == nil {
+			return prefix.String()
+		}
+	}
+	return addr.String()
+}
+
+// clientAddr walks the proxy chain back from the connection's peer and
+// returns the first address that is not a trusted proxy. A hop that cannot
+// be parsed, such as an obfuscated Forwarded identifier, ends the walk at the
+// last trusted proxy.
+func clientAddr(r *http.Request, trusted []netip.Prefix, header string) netip.Addr {
+	client := parseNode(r.RemoteAddr)
+	if !client.IsValid() || !isTrusted(client, trusted) {
+		return client
+	}
+
+	hops := forwardedHops(r.Header, header)
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := parseNode(hops[i])
+		if !hop.IsValid() {
+			break
+		}
+		client = hop
+		if !isTrusted(hop, trusted) {
+			break
+		}
+	}
+	return client
+}
+
+// forwardedHops lists the addresses the request was forwarded for, from the
+// client to the last proxy, as recorded in header: one of the
+// limiter.ClientIPHeader values, X-Forwarded-For when empty.
+func forwardedHops(h http.Header, header string) []string {
+	switch header {
+	case limiter.ClientIPHeaderForwarded:
+		var hops []string
+		for _, element := range strings.Split(strings.Join(h.Values("Forwarded"), ","), ",") {
+			hops = append(hops, forwardedFor(element))
+		}
+		return hops
+	case limiter.ClientIPHeaderXRealIP:
+		if xri := h.Get("X-Real-IP"); xri != "" {
+			return []string{xri}
+		}
+		return nil
+	default:
+		if values := h.Values("X-Forwarded-For"); len(values) > 0 {
+			return strings.Split(strings.Join(values, ","), ",")
+		}
+		return nil
+	}
+}
+
+// forwardedFor is the for= parameter of a Forwarded element, or "" when it
+// has none.
+func forwardedFor(element string) string {
+	for _, pair := range strings.Split(element, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && strings.EqualFold(name, "for") {
+			return strings.Trim(value, `"`)
+		}
+	}
+	return ""
+}
+
+// parseNode parses an address with or without a port, IPv6 addresses
+// possibly in brackets, as found in RemoteAddr and forwarding headers.
+func parseNode(node string) netip.Addr {
+	node = strings.TrimSpace(node)
+	if addrPort, err := netip.ParseAddrPort(node); err == nil {
+		return addrPort.Addr().Unmap().WithZone("")
+	}
+	node = strings.TrimSuffix(strings.TrimPrefix(node, "["), "]")
+	if addr, err := netip.ParseAddr(node); err == nil {
+		return addr.Unmap().WithZone("")
+	}
+	return netip.Addr{}
+}
+
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
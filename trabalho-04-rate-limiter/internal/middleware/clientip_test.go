@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"rate-limiter/internal/limiter"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("2001:db8:ffff::/48"),
+	}
+
+	xff, forwarded, realIP := limiter.ClientIPHeaderXFF, limiter.ClientIPHeaderForwarded, limiter.ClientIPHeaderXRealIP
+	tests := []struct {
+		name       string
+		header     string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"no headers", xff, "203.0.113.7:4000", nil, "203.0.113.7"},
+		{"untrusted peer", xff, "203.0.113.7:4000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "203.0.113.7"},
+		{"trusted peer", xff, "10.0.0.1:4000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+		{"default header", "", "10.0.0.1:4000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+		{"spoofed first entry", xff, "10.0.0.1:4000", map[string]string{"X-Forwarded-For": "1.1.1.1, 198.51.100.1"}, "198.51.100.1"},
+		{"proxy chain", xff, "10.0.0.1:4000", map[string]string{"X-Forwarded-For": "1.1.1.1, 198.51.100.1, 10.0.0.2"}, "198.51.100.1"},
+		{"only proxies", xff, "10.0.0.1:4000", map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		{"unparseable hop", xff, "10.0.0.1:4000", map[string]string{"X-Forwarded-For": "198.51.100.1, unknown, 10.0.0.2"}, "10.0.0.2"},
+		{"spoofed forwarded with xff", xff, "10.0.0.1:4000", map[string]string{"Forwarded": "for=1.1.1.1", "X-Forwarded-For": "198.51.100.2"}, "198.51.100.2"},
+		{"spoofed forwarded without xff", xff, "10.0.0.1:4000", map[string]string{"Forwarded": "for=1.1.1.1"}, "10.0.0.1"},
+		{"spoofed real ip", xff, "10.0.0.1:4000", map[string]string{"X-Real-IP": "1.1.1.1"}, "10.0.0.1"},
+		{"real ip", realIP, "10.0.0.1:4000", map[string]string{"X-Real-IP": "198.51.100.1"}, "198.51.100.1"},
+		{"real ip ignores xff", realIP, "10.0.0.1:4000", map[string]string{"X-Real-IP": "198.51.100.1", "X-Forwarded-For": "1.1.1.1"}, "198.51.100.1"},
+		{"forwarded", forwarded, "10.0.0.1:4000", map[string]string{"Forwarded": `for=1.1.1.1, for=198.51.100.1;proto=https, for="10.0.0.2:80"`}, "198.51.100.1"},
+		{"forwarded ignores xff", forwarded, "10.0.0.1:4000", map[string]string{"Forwarded": "for=198.51.100.1", "X-Forwarded-For": "1.1.1.1"}, "198.51.100.1"},
+		{"forwarded ipv6", forwarded, "10.0.0.1:4000", map[string]string{"Forwarded": `For="[2001:db8:cafe::17]:4711"`}, "2001:db8:cafe::/64"},
+		{"forwarded obfuscated", forwarded, "10.0.0.1:4000", map[string]string{"Forwarded": "for=_hidden"}, "10.0.0.1"},
+		{"ipv6 peer", xff, "[2001:db8:1:2:3:4:5:6]:4000", nil, "2001:db8:1:2::/64"},
+		{"trusted ipv6 peer", xff, "[2001:db8:ffff::1]:4000", map[string]string{"X-Forwarded-For": "2001:db8:1:2::9"}, "2001:db8:1:2::/64"},
+		{"ipv4 mapped", xff, "[::ffff:203.0.113.7]:4000", nil, "203.0.113.7"},
+		{"invalid peer", xff, "pipe", nil, "127.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &limiter.Config{TrustedProxies: trusted, ClientIPHeader: tt.header, IPv6PrefixLength: 64}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/", nil)
+			c.Request.RemoteAddr = tt.remoteAddr
+			for name, value := range tt.headers {
+				c.Request.Header.Set(name, value)
+			}
+
+			if got := ClientIP(c, config); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClientIP_IPv6PrefixLength(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	c.Request.RemoteAddr = "[2001:db8:1:2:3:4:5:6]:4000"
+
+	for length, want := range map[int]string{
+		0:   "2001:db8:1:2:3:4:5:6",
+		48:  "2001:db8:1::/48",
+		128: "2001:db8:1:2:3:4:5:6",
+	} {
+		if got := ClientIP(c, &limiter.Config{IPv6PrefixLength: length}); got != want {
+			t.Errorf("Expected %s for /%d, got %s", want, length, got)
+		}
+	}
+}
@@ -2,8 +2,9 @@ package middleware
 
 import (
 	"context"
+	"log"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 
 	"rate-limiter/internal/limiter"
@@ -11,16 +12,49 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RateLimitMiddleware(rateLimiter *limiter.RateLimiter) gin.HandlerFunc {
+// Option configures RateLimitMiddleware.
+type Option func(*options)
+
+type options struct {
+	route *limiter.RoutePolicy
+}
+
+// WithRoutePolicy applies route to every request the middleware handles,
+// instead of the route policies in the limiter configuration. It is meant for
+// Gin groups registering their own middleware; route.Name keeps the group's
+// counters apart from other groups'. The route is registered with the limiter
+// so its Reset covers it.
+func WithRoutePolicy(route limiter.RoutePolicy) Option {
+	return func(o *options) {
+		o.route = &route
+	}
+}
+
+func RateLimitMiddleware(rateLimiter *limiter.RateLimiter, opts ...Option) gin.HandlerFunc {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.route != nil {
+		rateLimiter.RegisterRoute(*o.route)
+	}
+
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 		defer cancel()
 
-		ip := getClientIP(c)
+		ip := ClientIP(c, rateLimiter.Config())
 		apiKey := c.GetHeader("API_KEY")
-		
-		allowed, err := rateLimiter.CheckRequest(ctx, ip, apiKey)
+
+		decision, err := rateLimiter.Check(ctx, limiter.Request{
+			IP:     ip,
+			Token:  apiKey,
+			Method: c.Request.Method,
+			Path:   routePath(c),
+			Route:  o.route,
+		})
 		if err != nil {
+			log.Printf("Rate limit check failed: %v", err)
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error": "you have reached the maximum number of requests or actions allowed within a certain time frame",
 			})
@@ -28,39 +62,57 @@ func RateLimitMiddleware(rateLimiter *limiter.RateLimiter) gin.HandlerFunc {
 			return
 		}
 
-		if !allowed {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "you have reached the maximum number of requests or actions allowed within a certain time frame",
+		if decision.Denied() {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "access denied",
 			})
 			c.Abort()
 			return
 		}
 
-		remaining, err := rateLimiter.GetRemainingRequests(ctx, ip, apiKey)
-		if err == nil {
-			c.Header("X-RateLimit-Remaining", string(rune(remaining)))
+		setRateLimitHeaders(c, decision)
+
+		if !decision.Allowed {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": "you have reached the maximum number of requests or actions allowed within a certain time frame",
+			})
+			c.Abort()
+			return
 		}
 
 		c.Next()
 	}
 }
 
-func getClientIP(c *gin.Context) string {
-	if xff := c.GetHeader("X-Forwarded-For"); xff != "" {
-		ips := strings.Split(xff, ",")
-		if len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
-		}
+// setRateLimitHeaders writes the RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers of the IETF RateLimit header fields draft, and
+// Retry-After on rejections, all in whole seconds rounded up.
+func setRateLimitHeaders(c *gin.Context, decision *limiter.Decision) {
+	if decision.Limit == 0 {
+		return
 	}
 
-	if xri := c.GetHeader("X-Real-IP"); xri != "" {
-		return xri
+	c.Header("RateLimit-Limit", strconv.FormatInt(decision.Limit, 10))
+	c.Header("RateLimit-Remaining", strconv.FormatInt(decision.Remaining, 10))
+	c.Header("RateLimit-Reset", strconv.FormatInt(seconds(decision.Reset), 10))
+	if !decision.Allowed {
+		retryAfter := seconds(decision.RetryAfter)
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
 	}
+}
 
-	ip := c.ClientIP()
-	if ip == "" {
-		return "127.0.0.1"
-	}
+func seconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
 
-	return ip
+// routePath is the Gin route pattern the request matched, such as
+// /api/users/:id, or its URL path when it matched none.
+func routePath(c *gin.Context) string {
+	if path := c.FullPath(); path != "" {
+		return path
+	}
+	return c.Request.URL.Path
 }
@@ -0,0 +1,25 @@
+package storage
+
+import "fmt"
+
+// AccessList names a list of entries that Check looks up before anything
+// else: entries in AllowList skip rate limiting and entries in DenyList are
+// rejected outright.
+type AccessList string
+
+const (
+	AllowList AccessList = "allow"
+	DenyList  AccessList = "deny"
+)
+
+func (l AccessList) validate() error {
+	if l != AllowList && l != DenyList {
+		return fmt.Errorf("unknown access list %q", l)
+	}
+	return nil
+}
+
+// accessKey is the Redis set holding list's entries.
+func accessKey(list AccessList) string {
+	return fmt.Sprintf("access:%s", list)
+}
@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Algorithm selects how Take counts requests against a Limit.
+type Algorithm string
+
+const (
+	// FixedWindow counts requests in windows that start with the first
+	// request, so up to twice the limit can pass around a window edge.
+	FixedWindow Algorithm = "fixed_window"
+	// SlidingWindowCounter weights the previous window's count by how much
+	// of it still overlaps the sliding window.
+	SlidingWindowCounter Algorithm = "sliding_window_counter"
+	// SlidingLog keeps the time of every request in the window; exact, but
+	// memory grows with the limit.
+	SlidingLog Algorithm = "sliding_log"
+	// TokenBucket refills Requests tokens per Window, allowing bursts of up
+	// to Requests after a quiet period.
+	TokenBucket Algorithm = "token_bucket"
+	// GCRA (generic cell rate algorithm) spaces requests Window/Requests
+	// apart with a burst tolerance of Requests, keeping a single timestamp.
+	GCRA Algorithm = "gcra"
+)
+
+var algorithms = []Algorithm{FixedWindow, SlidingWindowCounter, SlidingLog, TokenBucket, GCRA}
+
+func ParseAlgorithm(name string) (Algorithm, error) {
+	for _, algorithm := range algorithms {
+		if string(algorithm) == name {
+			return algorithm, nil
+		}
+	}
+	return "", fmt.Errorf("unknown rate limit algorithm %q", name)
+}
+
+// Limit allows Requests per Window using Algorithm.
+type Limit struct {
+	Algorithm Algorithm
+	Requests  int64
+	Window    time.Duration
+}
+
+func (l Limit) validate() error {
+	if _, err := ParseAlgorithm(string(l.Algorithm)); err != nil {
+		return err
+	}
+	if l.Requests <= 0 {
+		return fmt.Errorf("rate limit must allow at least one request, got %d", l.Requests)
+	}
+	if l.Window < time.Millisecond {
+		return fmt.Errorf("rate limit window must be at least 1ms, got %v", l.Window)
+	}
+	return nil
+}
+
+// Result is the outcome of Take. Remaining is what is left after the call;
+// ResetAfter is when the key is back to its full limit and RetryAfter, set
+// only when the call was not allowed, when the same call would be.
+type Result struct {
+	Allowed    bool
+	Remaining  int64
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+// limitState is the in-memory state of one key; each algorithm uses only
+// the fields it needs.
+type limitState struct {
+	algorithm Algorithm
+	count     int64
+	previous  int64
+	window    time.Time
+	log       []time.Time
+	tokens    float64
+	updated   time.Time
+	tat       time.Time
+	expires   time.Time
+}
+
+// take applies n requests at now. With n zero it only reports the current
+// state. It returns false when the limit was not consumed, in which case the
+// state must not be stored for a key that had none.
+func (s *limitState) take(limit Limit, n int64, now time.Time) (Result, bool) {
+	switch limit.Algorithm {
+	case SlidingWindowCounter:
+		return s.slidingWindowCounter(limit, n, now)
+	case SlidingLog:
+		return s.slidingLog(limit, n, now)
+	case TokenBucket:
+		return s.tokenBucket(limit, n, now)
+	case GCRA:
+		return s.gcra(limit, n, now)
+	default:
+		return s.fixedWindow(limit, n, now)
+	}
+}
+
+func (s *limitState) fixedWindow(limit Limit, n int64, now time.Time) (Result, bool) {
+	if !now.Before(s.window) {
+		s.count = 0
+		s.window = now.Add(limit.Window)
+	}
+	reset := s.window.Sub(now)
+	if s.count+n > limit.Requests {
+		return Result{Remaining: remaining(limit.Requests - s.count), ResetAfter: reset, RetryAfter: reset}, false
+	}
+	s.count += n
+	s.expires = s.window
+	return Result{Allowed: true, Remaining: remaining(limit.Requests - s.count), ResetAfter: reset}, n > 0
+}
+
+func (s *limitState) slidingWindowCounter(limit Limit, n int64, now time.Time) (Result, bool) {
+	start := now.Truncate(limit.Window)
+	if !start.Equal(s.window) {
+		if start.Sub(s.window) == limit.Window {
+			s.previous = s.count
+		} else {
+			s.previous = 0
+		}
+		s.count = 0
+		s.window = start
+	}
+	elapsed := now.Sub(start)
+	reset := limit.Window - elapsed
+	weight := float64(reset) / float64(limit.Window)
+	estimate := float64(s.previous)*weight + float64(s.count)
+	if estimate+float64(n) > float64(limit.Requests) {
+		retry := reset
+		if s.count+n <= limit.Requests {
+			// The previous window's share has to shrink enough to fit n.
+			free := float64(limit.Requests-s.count-n) / float64(s.previous)
+			retry = time.Duration(float64(limit.Window)*(1-free)) - elapsed
+		} else if n <= limit.Requests && s.count > limit.Requests-n {
+			// This window becomes the previous one and has to shrink too.
+			free := float64(limit.Requests-n) / float64(s.count)
+			retry += time.Duration(float64(limit.Window) * (1 - free))
+		}
+		return Result{Remaining: remaining(limit.Requests - ceil(estimate)), ResetAfter: reset, RetryAfter: retry}, false
+	}
+	s.count += n
+	s.expires = start.Add(2 * limit.Window)
+	estimate += float64(n)
+	return Result{Allowed: true, Remaining: remaining(limit.Requests - ceil(estimate)), ResetAfter: reset}, n > 0
+}
+
+func (s *limitState) slidingLog(limit Limit, n int64, now time.Time) (Result, bool) {
+	expired := 0
+	for expired < len(s.log) && !s.log[expired].After(now.Add(-limit.Window)) {
+		expired++
+	}
+	s.log = s.log[expired:]
+
+	count := int64(len(s.log))
+	reset := time.Duration(0)
+	if count > 0 {
+		reset = s.log[count-1].Add(limit.Window).Sub(now)
+	}
+	if count+n > limit.Requests {
+		retry := limit.Window
+		if n <= limit.Requests {
+			retry = s.log[count+n-limit.Requests-1].Add(limit.Window).Sub(now)
+		}
+		return Result{Remaining: remaining(limit.Requests - count), ResetAfter: reset, RetryAfter: retry}, false
+	}
+	for i := int64(0); i < n; i++ {
+		s.log = append(s.log, now)
+	}
+	if n > 0 {
+		reset = limit.Window
+	}
+	s.expires = now.Add(reset)
+	return Result{Allowed: true, Remaining: remaining(limit.Requests - count - n), ResetAfter: reset}, n > 0
+}
+
+func (s *limitState) tokenBucket(limit Limit, n int64, now time.Time) (Result, bool) {
+	perToken := float64(limit.Window) / float64(limit.Requests)
+	if s.updated.IsZero() {
+		s.tokens = float64(limit.Requests)
+	} else {
+		s.tokens += float64(now.Sub(s.updated)) / perToken
+		if s.tokens > float64(limit.Requests) {
+			s.tokens = float64(limit.Requests)
+		}
+	}
+	s.updated = now
+	if s.tokens < float64(n) {
+		return Result{
+			Remaining:  int64(s.tokens),
+			ResetAfter: time.Duration((float64(limit.Requests) - s.tokens) * perToken),
+			RetryAfter: time.Duration((float64(n) - s.tokens) * perToken),
+		}, false
+	}
+	s.tokens -= float64(n)
+	reset := time.Duration((float64(limit.Requests) - s.tokens) * perToken)
+	s.expires = now.Add(reset)
+	return Result{Allowed: true, Remaining: int64(s.tokens), ResetAfter: reset}, n > 0
+}
+
+func (s *limitState) gcra(limit Limit, n int64, now time.Time) (Result, bool) {
+	interval := limit.Window / time.Duration(limit.Requests)
+	if interval <= 0 {
+		interval = 1
+	}
+	tat := s.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	next := tat.Add(time.Duration(n) * interval)
+	if allowAt := next.Add(-limit.Window); now.Before(allowAt) {
+		return Result{
+			Remaining:  remaining(int64((limit.Window - tat.Sub(now)) / interval)),
+			ResetAfter: tat.Sub(now),
+			RetryAfter: allowAt.Sub(now),
+		}, false
+	}
+	s.tat = next
+	s.expires = next
+	return Result{
+		Allowed:    true,
+		Remaining:  remaining(int64((limit.Window - next.Sub(now)) / interval)),
+		ResetAfter: next.Sub(now),
+	}, n > 0
+}
+
+func remaining(n int64) int64 {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+func ceil(f float64) int64 {
+	i := int64(f)
+	if float64(i) < f {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type takeStep struct {
+	at        time.Duration
+	allowed   bool
+	remaining int64
+	retry     time.Duration
+}
+
+func TestMemoryStorage_TakeAlgorithms(t *testing.T) {
+	ms := time.Millisecond
+
+	tests := map[Algorithm][]takeStep{
+		FixedWindow: {
+			{at: 0, allowed: true, remaining: 1},
+			{at: 0, allowed: true, remaining: 0},
+			{at: 500 * ms, retry: 500 * ms},
+			{at: 1000 * ms, allowed: true, remaining: 1},
+		},
+		SlidingWindowCounter: {
+			{at: 0, allowed: true, remaining: 1},
+			{at: 0, allowed: true, remaining: 0},
+			{at: 500 * ms, retry: 1000 * ms},
+			{at: 1500 * ms, allowed: true, remaining: 0},
+			{at: 1750 * ms, retry: 250 * ms},
+			{at: 2000 * ms, allowed: true, remaining: 0},
+		},
+		SlidingLog: {
+			{at: 0, allowed: true, remaining: 1},
+			{at: 500 * ms, allowed: true, remaining: 0},
+			{at: 900 * ms, retry: 100 * ms},
+			{at: 1000 * ms, allowed: true, remaining: 0},
+			{at: 1200 * ms, retry: 300 * ms},
+		},
+		TokenBucket: {
+			{at: 0, allowed: true, remaining: 1},
+			{at: 0, allowed: true, remaining: 0},
+			{at: 250 * ms, retry: 250 * ms},
+			{at: 500 * ms, allowed: true, remaining: 0},
+			{at: 1500 * ms, allowed: true, remaining: 1},
+		},
+		GCRA: {
+			{at: 0, allowed: true, remaining: 1},
+			{at: 0, allowed: true, remaining: 0},
+			{at: 250 * ms, retry: 250 * ms},
+			{at: 500 * ms, allowed: true, remaining: 0},
+			{at: 2000 * ms, allowed: true, remaining: 1},
+		},
+	}
+
+	for algorithm, steps := range tests {
+		t.Run(string(algorithm), func(t *testing.T) {
+			storage := NewMemoryStorage()
+			start := time.Unix(1700000000, 0)
+			limit := Limit{Algorithm: algorithm, Requests: 2, Window: time.Second}
+
+			for i, step := range steps {
+				storage.now = func() time.Time { return start.Add(step.at) }
+
+				result, err := storage.Take(context.Background(), "test", limit, 1)
+				if err != nil {
+					t.Fatalf("Step %d: expected no error, got %v", i, err)
+				}
+				if result.Allowed != step.allowed {
+					t.Errorf("Step %d: expected allowed %v, got %v", i, step.allowed, result.Allowed)
+				}
+				if result.Remaining != step.remaining {
+					t.Errorf("Step %d: expected remaining %d, got %d", i, step.remaining, result.Remaining)
+				}
+				if result.RetryAfter != step.retry {
+					t.Errorf("Step %d: expected retry after %v, got %v", i, step.retry, result.RetryAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStorage_TakeWithoutCostDoesNotCount(t *testing.T) {
+	storage := NewMemoryStorage()
+	ctx := context.Background()
+
+	for _, algorithm := range algorithms {
+		limit := Limit{Algorithm: algorithm, Requests: 3, Window: time.Second}
+		key := string(algorithm)
+
+		result, err := storage.Take(ctx, key, limit, 0)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", algorithm, err)
+		}
+		if !result.Allowed || result.Remaining != 3 {
+			t.Errorf("%s: expected a fresh key to have 3 remaining, got %+v", algorithm, result)
+		}
+
+		if _, err := storage.Take(ctx, key, limit, 1); err != nil {
+			t.Fatalf("%s: expected no error, got %v", algorithm, err)
+		}
+		for i := 0; i < 2; i++ {
+			result, err = storage.Take(ctx, key, limit, 0)
+			if err != nil {
+				t.Fatalf("%s: expected no error, got %v", algorithm, err)
+			}
+			if result.Remaining != 2 {
+				t.Errorf("%s: expected 2 remaining, got %d", algorithm, result.Remaining)
+			}
+		}
+	}
+}
+
+func TestMemoryStorage_TakeResetsOnDelete(t *testing.T) {
+	storage := NewMemoryStorage()
+	ctx := context.Background()
+	limit := Limit{Algorithm: TokenBucket, Requests: 1, Window: time.Minute}
+
+	if result, _ := storage.Take(ctx, "test", limit, 1); !result.Allowed {
+		t.Fatal("Expected the first request to be allowed")
+	}
+	if result, _ := storage.Take(ctx, "test", limit, 1); result.Allowed {
+		t.Fatal("Expected the second request to be rejected")
+	}
+
+	if err := storage.Delete(ctx, "test"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result, _ := storage.Take(ctx, "test", limit, 1); !result.Allowed {
+		t.Error("Expected a request to be allowed after delete")
+	}
+}
+
+func TestMemoryStorage_TakeRejectsInvalidLimits(t *testing.T) {
+	storage := NewMemoryStorage()
+
+	for _, limit := range []Limit{
+		{Algorithm: GCRA, Requests: 0, Window: time.Second},
+		{Algorithm: TokenBucket, Requests: 1, Window: 0},
+	} {
+		if _, err := storage.Take(context.Background(), "test", limit, 1); err == nil {
+			t.Errorf("Expected an error for %+v", limit)
+		}
+	}
+}
+
+func TestParseAlgorithm(t *testing.T) {
+	for _, algorithm := range algorithms {
+		parsed, err := ParseAlgorithm(string(algorithm))
+		if err != nil || parsed != algorithm {
+			t.Errorf("Expected %s to parse, got %q, %v", algorithm, parsed, err)
+		}
+	}
+
+	if _, err := ParseAlgorithm("leaky_bucket"); err == nil {
+		t.Error("Expected an error for an unknown algorithm")
+	}
+}
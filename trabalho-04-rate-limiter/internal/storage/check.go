@@ -0,0 +1,48 @@
+package storage
+
+import "time"
+
+// Check is everything one request is checked against, evaluated by
+// Storage.Check as a single atomic step.
+type Check struct {
+	// Access are the entries looked up first: when any is in DenyList the
+	// request is rejected, and otherwise when any is in AllowList it is let
+	// through, either way without looking at blocks or counters.
+	Access []string
+	// Blocks are keys that reject the request while any of them is blocked.
+	Blocks []string
+	// Counters each count the request, in order, until one rejects it.
+	Counters []Counter
+}
+
+// Counter counts requests under Key against Limit. When it rejects a request
+// and BlockDuration is positive, Key is blocked for that long.
+type Counter struct {
+	Key           string
+	Limit         Limit
+	BlockDuration time.Duration
+}
+
+// CheckResult is the outcome of a Check. Access is the list that decided
+// it, if one did. Blocked is the index in
+// Check.Blocks of the key whose block rejected the request and Rejected the
+// index of the counter that did, each -1 otherwise. Results has one entry per
+// counter evaluated, and BlockUntil is set whenever the request was rejected
+// by a block or caused one.
+type CheckResult struct {
+	Allowed    bool
+	Access     AccessList
+	Blocked    int
+	Rejected   int
+	Results    []Result
+	BlockUntil time.Time
+}
+
+func (c Check) validate() error {
+	for _, counter := range c.Counters {
+		if err := counter.Limit.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
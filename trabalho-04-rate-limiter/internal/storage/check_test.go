@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMemoryStorage_Check(t *testing.T) {
+	testStorageCheck(t, NewMemoryStorage())
+}
+
+// TestRedisStorage_Check runs against the Redis server at REDIS_TEST_ADDR
+// (host:port), which it flushes first.
+func TestRedisStorage_Check(t *testing.T) {
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR not set")
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("Invalid REDIS_TEST_ADDR: %v", err)
+	}
+
+	storage, err := NewRedisStorage(host, port, "", 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer storage.Close()
+	if err := storage.client.FlushDB(context.Background()).Err(); err != nil {
+		t.Fatalf("Failed to flush Redis: %v", err)
+	}
+	// A flushed script cache must not break the scripts.
+	if err := storage.client.ScriptFlush(context.Background()).Err(); err != nil {
+		t.Fatalf("Failed to flush Redis scripts: %v", err)
+	}
+
+	testStorageCheck(t, storage)
+
+	for _, algorithm := range algorithms {
+		limit := Limit{Algorithm: algorithm, Requests: 2, Window: time.Minute}
+		for i, allowed := range []bool{true, true, false} {
+			result, err := storage.Take(context.Background(), "take:"+string(algorithm), limit, 1)
+			if err != nil {
+				t.Fatalf("%s: expected no error, got %v", algorithm, err)
+			}
+			if result.Allowed != allowed {
+				t.Errorf("%s: expected request %d allowed to be %v", algorithm, i+1, allowed)
+			}
+		}
+	}
+}
+
+func testStorageCheck(t *testing.T, storage Storage) {
+	ctx := context.Background()
+	strict := Counter{Key: "route:POST /api/data:ip:10.0.0.1", Limit: Limit{Algorithm: SlidingLog, Requests: 1, Window: time.Minute}, BlockDuration: time.Minute}
+	client := Counter{Key: "ip:10.0.0.1", Limit: Limit{Algorithm: TokenBucket, Requests: 2, Window: time.Minute}}
+	check := Check{Blocks: []string{"ip:10.0.0.1", strict.Key}, Counters: []Counter{strict, client}}
+
+	result, err := storage.Check(ctx, check)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.Allowed || result.Blocked != -1 || result.Rejected != -1 || len(result.Results) != 2 {
+		t.Fatalf("Expected the first request to be allowed by both counters, got %+v", result)
+	}
+	if result.Results[0].Remaining != 0 || result.Results[1].Remaining != 1 {
+		t.Errorf("Expected 0 and 1 remaining, got %+v", result.Results)
+	}
+
+	result, err = storage.Check(ctx, check)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Allowed || result.Rejected != 0 || len(result.Results) != 1 || result.BlockUntil.IsZero() {
+		t.Fatalf("Expected the strict counter to reject and block, got %+v", result)
+	}
+
+	result, err = storage.Check(ctx, check)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Allowed || result.Blocked != 1 || len(result.Results) != 0 {
+		t.Fatalf("Expected the strict counter's block to reject, got %+v", result)
+	}
+
+	// The rejected requests were not counted by the client counter.
+	result, err = storage.Check(ctx, Check{Blocks: []string{"ip:10.0.0.1"}, Counters: []Counter{client}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.Allowed || result.Results[0].Remaining != 0 {
+		t.Fatalf("Expected the client counter to allow its second request, got %+v", result)
+	}
+
+	result, err = storage.Check(ctx, Check{Counters: []Counter{client}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Allowed || result.Rejected != 0 || !result.BlockUntil.IsZero() || result.Results[0].RetryAfter <= 0 {
+		t.Errorf("Expected a rejection without block, with a retry time, got %+v", result)
+	}
+
+	if _, err := storage.Check(ctx, Check{Counters: []Counter{{Key: "x", Limit: Limit{Algorithm: "leaky", Requests: 1, Window: time.Second}}}}); err == nil {
+		t.Error("Expected an error for an unknown algorithm")
+	}
+
+	testStorageAccess(t, storage, check)
+}
+
+func testStorageAccess(t *testing.T, storage Storage, blocked Check) {
+	ctx := context.Background()
+	if err := storage.AddAccess(ctx, AllowList, "ip:10.0.0.0/8"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := storage.AddAccess(ctx, DenyList, "token:abuser"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := storage.AddAccess(ctx, "maybe", "token:abuser"); err == nil {
+		t.Error("Expected an error for an unknown list")
+	}
+
+	entries, err := storage.AccessEntries(ctx, AllowList)
+	if err != nil || len(entries) != 1 || entries[0] != "ip:10.0.0.0/8" {
+		t.Fatalf("Expected the allow list to hold ip:10.0.0.0/8, got %v, %v", entries, err)
+	}
+
+	// The blocked check from testStorageCheck is let through when allowed.
+	blocked.Access = []string{"ip:10.0.0.1/32", "ip:10.0.0.0/8"}
+	result, err := storage.Check(ctx, blocked)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.Allowed || result.Access != AllowList || len(result.Results) != 0 {
+		t.Fatalf("Expected the allow list to let the request through, got %+v", result)
+	}
+
+	// The deny list wins over the allow list.
+	blocked.Access = append(blocked.Access, "token:abuser")
+	result, err = storage.Check(ctx, blocked)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Allowed || result.Access != DenyList {
+		t.Fatalf("Expected the deny list to reject the request, got %+v", result)
+	}
+
+	if err := storage.RemoveAccess(ctx, DenyList, "token:abuser"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := storage.RemoveAccess(ctx, AllowList, "ip:10.0.0.0/8"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	result, err = storage.Check(ctx, blocked)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Allowed || result.Access != "" || result.Blocked != 1 {
+		t.Errorf("Expected the block to reject the request once unlisted, got %+v", result)
+	}
+}
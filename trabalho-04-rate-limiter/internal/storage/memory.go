@@ -2,84 +2,128 @@ package storage
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 )
 
 type MemoryStorage struct {
-	data   map[string]*LimiterData
-	blocks map[string]time.Time
-	mutex  sync.RWMutex
+	blocks    map[string]time.Time
+	limits    map[string]*limitState
+	access    map[AccessList]map[string]bool
+	lastSweep time.Time
+	now       func() time.Time
+	mutex     sync.RWMutex
 }
 
 func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
-		data:   make(map[string]*LimiterData),
 		blocks: make(map[string]time.Time),
+		limits: make(map[string]*limitState),
+		access: map[AccessList]map[string]bool{
+			AllowList: make(map[string]bool),
+			DenyList:  make(map[string]bool),
+		},
+		now: time.Now,
 	}
 }
 
-func (m *MemoryStorage) Get(ctx context.Context, key string) (*LimiterData, error) {
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
-
-	data, exists := m.data[key]
-	if !exists {
-		return nil, nil
+func (m *MemoryStorage) Take(ctx context.Context, key string, limit Limit, n int64) (*Result, error) {
+	if err := limit.validate(); err != nil {
+		return nil, err
 	}
 
-	return &LimiterData{
-		Count:      data.Count,
-		ResetTime:  data.ResetTime,
-		Blocked:    data.Blocked,
-		BlockUntil: data.BlockUntil,
-	}, nil
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	result := m.take(key, limit, n, now)
+	return &result, nil
 }
 
-func (m *MemoryStorage) Set(ctx context.Context, key string, data *LimiterData, expiration time.Duration) error {
+func (m *MemoryStorage) Check(ctx context.Context, check Check) (*CheckResult, error) {
+	if err := check.validate(); err != nil {
+		return nil, err
+	}
+
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	m.data[key] = &LimiterData{
-		Count:      data.Count,
-		ResetTime:  data.ResetTime,
-		Blocked:    data.Blocked,
-		BlockUntil: data.BlockUntil,
+	now := m.now()
+	m.sweep(now)
+
+	result := &CheckResult{Blocked: -1, Rejected: -1}
+	for _, list := range []AccessList{DenyList, AllowList} {
+		for _, entry := range check.Access {
+			if m.access[list][entry] {
+				result.Access = list
+				result.Allowed = list == AllowList
+				return result, nil
+			}
+		}
 	}
 
-	go func() {
-		time.Sleep(expiration)
-		m.mutex.Lock()
-		delete(m.data, key)
-		m.mutex.Unlock()
-	}()
+	for i, key := range check.Blocks {
+		if blockUntil, exists := m.blocks[key]; exists && now.Before(blockUntil) {
+			result.Blocked = i
+			result.BlockUntil = blockUntil
+			return result, nil
+		}
+	}
 
-	return nil
+	for i, counter := range check.Counters {
+		counted := m.take(counter.Key, counter.Limit, 1, now)
+		result.Results = append(result.Results, counted)
+		if !counted.Allowed {
+			result.Rejected = i
+			if counter.BlockDuration > 0 {
+				result.BlockUntil = now.Add(counter.BlockDuration)
+				m.blocks[counter.Key] = result.BlockUntil
+			}
+			return result, nil
+		}
+	}
+
+	result.Allowed = true
+	return result, nil
 }
 
-func (m *MemoryStorage) Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
+// take must be called with the mutex held.
+func (m *MemoryStorage) take(key string, limit Limit, n int64, now time.Time) Result {
+	state, exists := m.limits[key]
+	if !exists || state.algorithm != limit.Algorithm || !now.Before(state.expires) {
+		state = &limitState{algorithm: limit.Algorithm}
+		exists = false
+	}
 
-	data, exists := m.data[key]
-	if !exists {
-		data = &LimiterData{
-			Count:     0,
-			ResetTime: time.Now().Add(expiration),
-		}
-		m.data[key] = data
+	result, taken := state.take(limit, n, now)
+	if taken && !exists {
+		m.limits[key] = state
 	}
 
-	data.Count++
+	return result
+}
 
-	go func() {
-		time.Sleep(expiration)
-		m.mutex.Lock()
-		delete(m.data, key)
-		m.mutex.Unlock()
-	}()
+// sweep drops expired limit states and blocks at most once per minute, so
+// keys that are never seen again do not pile up.
+func (m *MemoryStorage) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < time.Minute {
+		return
+	}
+	m.lastSweep = now
 
-	return data.Count, nil
+	for key, state := range m.limits {
+		if !now.Before(state.expires) {
+			delete(m.limits, key)
+		}
+	}
+	for key, blockUntil := range m.blocks {
+		if !now.Before(blockUntil) {
+			delete(m.blocks, key)
+		}
+	}
 }
 
 func (m *MemoryStorage) SetBlock(ctx context.Context, key string, blockUntil time.Time) error {
@@ -126,12 +170,52 @@ func (m *MemoryStorage) Delete(ctx context.Context, key string) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	delete(m.data, key)
 	delete(m.blocks, key)
+	delete(m.limits, key)
+
+	return nil
+}
+
+func (m *MemoryStorage) AddAccess(ctx context.Context, list AccessList, entry string) error {
+	if err := list.validate(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.access[list][entry] = true
+	return nil
+}
 
+func (m *MemoryStorage) RemoveAccess(ctx context.Context, list AccessList, entry string) error {
+	if err := list.validate(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.access[list], entry)
 	return nil
 }
 
+func (m *MemoryStorage) AccessEntries(ctx context.Context, list AccessList) ([]string, error) {
+	if err := list.validate(); err != nil {
+		return nil, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	entries := make([]string, 0, len(m.access[list]))
+	for entry := range m.access[list] {
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+	return entries, nil
+}
+
 func (m *MemoryStorage) Close() error {
 	return nil
 }
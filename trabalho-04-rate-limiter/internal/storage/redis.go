@@ -2,8 +2,8 @@ package storage
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -27,68 +27,129 @@ func NewRedisStorage(host, port, password string, db int) (*RedisStorage, error)
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
+	// Scripts run with EVALSHA; loading them up front saves the first calls a
+	// NOSCRIPT round trip, and Script.Run falls back to EVAL, which loads the
+	// script again, if Redis drops its cache (a restart or SCRIPT FLUSH).
+	for _, script := range scripts {
+		if err := script.Load(ctx, rdb).Err(); err != nil {
+			return nil, fmt.Errorf("failed to load Redis scripts: %w", err)
+		}
+	}
+
 	return &RedisStorage{client: rdb}, nil
 }
 
-func (r *RedisStorage) Get(ctx context.Context, key string) (*LimiterData, error) {
-	val, err := r.client.Get(ctx, key).Result()
-	if err != nil {
-		if err == redis.Nil {
-			return nil, nil
-		}
+func (r *RedisStorage) Take(ctx context.Context, key string, limit Limit, n int64) (*Result, error) {
+	if err := limit.validate(); err != nil {
 		return nil, err
 	}
 
-	var data LimiterData
-	if err := json.Unmarshal([]byte(val), &data); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal limiter data: %w", err)
+	values, err := takeScript.Run(ctx, r.client, []string{stateKey(key, limit.Algorithm)},
+		string(limit.Algorithm), limit.Requests, limit.Window.Milliseconds(), n).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s script: %w", limit.Algorithm, err)
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("unexpected %s script result %v", limit.Algorithm, values)
 	}
 
-	return &data, nil
+	result := scriptResult(values)
+	return &result, nil
 }
 
-func (r *RedisStorage) Set(ctx context.Context, key string, data *LimiterData, expiration time.Duration) error {
-	jsonData, err := json.Marshal(data)
+func (r *RedisStorage) Check(ctx context.Context, check Check) (*CheckResult, error) {
+	if err := check.validate(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, 2+len(check.Blocks)+2*len(check.Counters))
+	keys = append(keys, accessKey(DenyList), accessKey(AllowList))
+	for _, key := range check.Blocks {
+		keys = append(keys, blockKey(key))
+	}
+	args := []interface{}{len(check.Blocks), len(check.Access)}
+	for _, entry := range check.Access {
+		args = append(args, entry)
+	}
+	for _, counter := range check.Counters {
+		keys = append(keys, stateKey(counter.Key, counter.Limit.Algorithm), blockKey(counter.Key))
+		args = append(args, string(counter.Limit.Algorithm), counter.Limit.Requests,
+			counter.Limit.Window.Milliseconds(), counter.BlockDuration.Milliseconds())
+	}
+
+	values, err := checkScript.Run(ctx, r.client, keys, args...).Int64Slice()
 	if err != nil {
-		return fmt.Errorf("failed to marshal limiter data: %w", err)
+		return nil, fmt.Errorf("failed to run check script: %w", err)
+	}
+	if len(values) < 3 || ((values[0] == 0 || values[0] == 1) && (len(values)-3)%4 != 0) {
+		return nil, fmt.Errorf("unexpected check script result %v", values)
+	}
+
+	result := &CheckResult{Blocked: -1, Rejected: -1}
+	switch values[0] {
+	case -2:
+		result.Access = DenyList
+		return result, nil
+	case 2:
+		result.Access = AllowList
+		result.Allowed = true
+		return result, nil
+	case -1:
+		result.Blocked = int(values[1] - 1)
+		result.BlockUntil = time.Now().Add(time.Duration(values[2]) * time.Millisecond)
+		return result, nil
+	case 0:
+		result.Rejected = int(values[1] - 1)
+		if values[2] > 0 {
+			result.BlockUntil = time.Now().Add(time.Duration(values[2]) * time.Millisecond)
+		}
+	default:
+		result.Allowed = true
+	}
+	for i := 3; i < len(values); i += 4 {
+		result.Results = append(result.Results, scriptResult(values[i:i+4]))
 	}
 
-	return r.client.Set(ctx, key, jsonData, expiration).Err()
+	return result, nil
 }
 
-func (r *RedisStorage) Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
-	pipe := r.client.Pipeline()
-	
-	incr := pipe.Incr(ctx, key)
-	pipe.Expire(ctx, key, expiration)
-	
-	_, err := pipe.Exec(ctx)
-	if err != nil {
-		return 0, err
+// scriptResult reads the {allowed, remaining, reset after, retry after}
+// result of an algorithm.
+func scriptResult(values []int64) Result {
+	return Result{
+		Allowed:    values[0] == 1,
+		Remaining:  remaining(values[1]),
+		ResetAfter: time.Duration(values[2]) * time.Millisecond,
+		RetryAfter: time.Duration(values[3]) * time.Millisecond,
 	}
+}
+
+// stateKey keeps each algorithm's state apart, since they store different
+// Redis types and switching a key's algorithm would otherwise fail.
+func stateKey(key string, algorithm Algorithm) string {
+	return fmt.Sprintf("%s:%s", key, algorithm)
+}
 
-	return incr.Val(), nil
+func blockKey(key string) string {
+	return fmt.Sprintf("block:%s", key)
 }
 
 func (r *RedisStorage) SetBlock(ctx context.Context, key string, blockUntil time.Time) error {
-	blockKey := fmt.Sprintf("block:%s", key)
 	duration := time.Until(blockUntil)
-	
+
 	if duration <= 0 {
 		return nil
 	}
 
-	return r.client.Set(ctx, blockKey, "blocked", duration).Err()
+	return r.client.Set(ctx, blockKey(key), "blocked", duration).Err()
 }
 
 func (r *RedisStorage) IsBlocked(ctx context.Context, key string) (bool, time.Time, error) {
-	blockKey := fmt.Sprintf("block:%s", key)
-	
-	ttl, err := r.client.TTL(ctx, blockKey).Result()
+	ttl, err := r.client.PTTL(ctx, blockKey(key)).Result()
 	if err != nil {
 		return false, time.Time{}, err
 	}
-	
+
 	if ttl <= 0 {
 		return false, time.Time{}, nil
 	}
@@ -98,16 +159,42 @@ func (r *RedisStorage) IsBlocked(ctx context.Context, key string) (bool, time.Ti
 }
 
 func (r *RedisStorage) Delete(ctx context.Context, key string) error {
-	blockKey := fmt.Sprintf("block:%s", key)
-	
 	pipe := r.client.Pipeline()
-	pipe.Del(ctx, key)
-	pipe.Del(ctx, blockKey)
-	
+	pipe.Del(ctx, blockKey(key))
+	for _, algorithm := range algorithms {
+		pipe.Del(ctx, stateKey(key, algorithm))
+	}
+
 	_, err := pipe.Exec(ctx)
 	return err
 }
 
+func (r *RedisStorage) AddAccess(ctx context.Context, list AccessList, entry string) error {
+	if err := list.validate(); err != nil {
+		return err
+	}
+	return r.client.SAdd(ctx, accessKey(list), entry).Err()
+}
+
+func (r *RedisStorage) RemoveAccess(ctx context.Context, list AccessList, entry string) error {
+	if err := list.validate(); err != nil {
+		return err
+	}
+	return r.client.SRem(ctx, accessKey(list), entry).Err()
+}
+
+func (r *RedisStorage) AccessEntries(ctx context.Context, list AccessList) ([]string, error) {
+	if err := list.validate(); err != nil {
+		return nil, err
+	}
+	entries, err := r.client.SMembers(ctx, accessKey(list)).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(entries)
+	return entries, nil
+}
+
 func (r *RedisStorage) Close() error {
 	return r.client.Close()
 }
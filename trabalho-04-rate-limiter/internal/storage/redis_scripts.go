@@ -0,0 +1,206 @@
+package storage
+
+import "github.com/redis/go-redis/v9"
+
+// scriptLibrary defines one Lua function per algorithm, each taking the
+// state key, the limit's requests and window (milliseconds) and the cost, and
+// returning {allowed, remaining, reset after, retry after} with durations in
+// milliseconds. Scripts read the clock with TIME so instances with skewed
+// clocks agree.
+const scriptLibrary = `
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local function fixed_window(key, limit, window, cost)
+	local count = tonumber(redis.call('GET', key) or '0')
+	local ttl = redis.call('PTTL', key)
+	local fresh = ttl < 0
+	if fresh then
+		count = 0
+		ttl = window
+	end
+	if count + cost > limit then
+		return {0, limit - count, ttl, ttl}
+	end
+	if cost > 0 then
+		if fresh then
+			redis.call('SET', key, cost, 'PX', window)
+			count = cost
+		else
+			count = redis.call('INCRBY', key, cost)
+		end
+	end
+	return {1, limit - count, ttl, 0}
+end
+
+local function sliding_window_counter(key, limit, window, cost)
+	local start = now - now % window
+	local state = redis.call('HMGET', key, 'window', 'count', 'previous')
+	local count = tonumber(state[2]) or 0
+	local previous = tonumber(state[3]) or 0
+	local last = tonumber(state[1])
+	if last ~= start then
+		if last == start - window then
+			previous = count
+		else
+			previous = 0
+		end
+		count = 0
+	end
+	local elapsed = now - start
+	local reset = window - elapsed
+	local estimate = previous * reset / window + count
+	if estimate + cost > limit then
+		local retry = reset
+		if count + cost <= limit then
+			retry = math.floor(window * (1 - (limit - count - cost) / previous)) - elapsed
+		elseif cost <= limit and count > limit - cost then
+			retry = retry + math.floor(window * (1 - (limit - cost) / count))
+		end
+		return {0, limit - math.ceil(estimate), reset, retry}
+	end
+	if cost > 0 then
+		count = count + cost
+		redis.call('HSET', key, 'window', start, 'count', count, 'previous', previous)
+		redis.call('PEXPIRE', key, 2 * window - elapsed)
+	end
+	return {1, limit - math.ceil(estimate + cost), reset, 0}
+end
+
+local function sliding_log(key, limit, window, cost)
+	redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+	local count = redis.call('ZCARD', key)
+	local reset = 0
+	local newest = redis.call('ZRANGE', key, -1, -1, 'WITHSCORES')
+	if newest[2] then
+		reset = tonumber(newest[2]) + window - now
+	end
+	if count + cost > limit then
+		local retry = window
+		if cost <= limit then
+			local index = count + cost - limit - 1
+			local oldest = redis.call('ZRANGE', key, index, index, 'WITHSCORES')
+			retry = tonumber(oldest[2]) + window - now
+		end
+		return {0, limit - count, reset, retry}
+	end
+	if cost > 0 then
+		for i = 1, cost do
+			redis.call('ZADD', key, now, now .. '-' .. (count + i))
+		end
+		redis.call('PEXPIRE', key, window)
+		reset = window
+	end
+	return {1, limit - count - cost, reset, 0}
+end
+
+local function token_bucket(key, limit, window, cost)
+	local per_token = window / limit
+	local state = redis.call('HMGET', key, 'tokens', 'updated')
+	local tokens = tonumber(state[1])
+	if tokens == nil then
+		tokens = limit
+	else
+		tokens = math.min(limit, tokens + (now - tonumber(state[2])) / per_token)
+	end
+	if tokens < cost then
+		return {0, math.floor(tokens), math.ceil((limit - tokens) * per_token), math.ceil((cost - tokens) * per_token)}
+	end
+	tokens = tokens - cost
+	local reset = math.ceil((limit - tokens) * per_token)
+	if cost > 0 then
+		redis.call('HSET', key, 'tokens', tokens, 'updated', now)
+		redis.call('PEXPIRE', key, math.max(reset, 1))
+	end
+	return {1, math.floor(tokens), reset, 0}
+end
+
+local function gcra(key, limit, window, cost)
+	local interval = window / limit
+	local tat = math.max(tonumber(redis.call('GET', key) or '0'), now)
+	local new_tat = tat + cost * interval
+	local allow_at = new_tat - window
+	if now < allow_at then
+		return {0, math.floor((window - (tat - now)) / interval + 1e-9), math.ceil(tat - now), math.ceil(allow_at - now)}
+	end
+	if cost > 0 then
+		redis.call('SET', key, new_tat, 'PX', math.ceil(new_tat - now))
+	end
+	return {1, math.floor((window - (new_tat - now)) / interval + 1e-9), math.ceil(new_tat - now), 0}
+end
+
+local algorithms = {
+	fixed_window = fixed_window,
+	sliding_window_counter = sliding_window_counter,
+	sliding_log = sliding_log,
+	token_bucket = token_bucket,
+	gcra = gcra,
+}
+`
+
+// takeScript counts ARGV[4] requests under KEYS[1] with algorithm ARGV[1],
+// requests ARGV[2] and window ARGV[3].
+var takeScript = redis.NewScript(scriptLibrary + `
+return algorithms[ARGV[1]](KEYS[1], tonumber(ARGV[2]), tonumber(ARGV[3]), tonumber(ARGV[4]))
+`)
+
+// checkScript evaluates a whole Check. KEYS holds the deny and allow list
+// sets, the block keys, then a state key and a block key per counter; ARGV
+// holds the number of block keys, the number of access entries, the entries,
+// then the algorithm, requests, window and block duration (milliseconds) of
+// each counter. It returns {-2, 0, 0} or {2, 0, 0} when the deny or the allow
+// list decides, {-1, index, block ttl} when a block rejects the request,
+// {0, index, block duration, results...} when a counter does and
+// {1, 0, 0, results...} otherwise, with 1-based indexes and four numbers per
+// evaluated counter in results.
+var checkScript = redis.NewScript(scriptLibrary + `
+local blocks = tonumber(ARGV[1])
+local entries = tonumber(ARGV[2])
+
+local function listed(list)
+	if redis.call('SCARD', list) == 0 then
+		return false
+	end
+	for i = 3, entries + 2 do
+		if redis.call('SISMEMBER', list, ARGV[i]) == 1 then
+			return true
+		end
+	end
+	return false
+end
+if listed(KEYS[1]) then
+	return {-2, 0, 0}
+end
+if listed(KEYS[2]) then
+	return {2, 0, 0}
+end
+
+for i = 1, blocks do
+	local ttl = redis.call('PTTL', KEYS[2 + i])
+	if ttl > 0 then
+		return {-1, i, ttl}
+	end
+end
+
+local reply = {1, 0, 0}
+for i = 1, (#ARGV - entries - 2) / 4 do
+	local arg = entries + 2 + (i - 1) * 4
+	local result = algorithms[ARGV[arg + 1]](KEYS[2 + blocks + 2 * i - 1], tonumber(ARGV[arg + 2]), tonumber(ARGV[arg + 3]), 1)
+	for _, value in ipairs(result) do
+		table.insert(reply, value)
+	end
+	if result[1] == 0 then
+		reply[1] = 0
+		reply[2] = i
+		local block = tonumber(ARGV[arg + 4])
+		if block > 0 then
+			redis.call('SET', KEYS[2 + blocks + 2 * i], 'blocked', 'PX', block)
+			reply[3] = block
+		end
+		return reply
+	end
+end
+return reply
+`)
+
+var scripts = []*redis.Script{takeScript, checkScript}
@@ -5,17 +5,18 @@ import (
 	"time"
 )
 
-type LimiterData struct {
-	Count      int64     `json:"count"`
-	ResetTime  time.Time `json:"reset_time"`
-	Blocked    bool      `json:"blocked"`
-	BlockUntil time.Time `json:"block_until"`
-}
-
 type Storage interface {
-	Get(ctx context.Context, key string) (*LimiterData, error)
-	Set(ctx context.Context, key string, data *LimiterData, expiration time.Duration) error
-	Increment(ctx context.Context, key string, expiration time.Duration) (int64, error)
+	// Take counts n requests for key against limit, atomically, and only if
+	// they fit. With n zero it reports the key's state without changing it.
+	Take(ctx context.Context, key string, limit Limit, n int64) (*Result, error)
+	// Check evaluates the blocks and counters of check, and the blocks the
+	// counters cause, atomically.
+	Check(ctx context.Context, check Check) (*CheckResult, error)
+	// AddAccess, RemoveAccess and AccessEntries manage the allow and deny
+	// lists Check looks entries up in.
+	AddAccess(ctx context.Context, list AccessList, entry string) error
+	RemoveAccess(ctx context.Context, list AccessList, entry string) error
+	AccessEntries(ctx context.Context, list AccessList) ([]string, error)
 	SetBlock(ctx context.Context, key string, blockUntil time.Time) error
 	IsBlocked(ctx context.Context, key string) (bool, time.Time, error)
 	Delete(ctx context.Context, key string) error